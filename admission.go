@@ -0,0 +1,142 @@
+package gocache
+
+import "time"
+
+// NoMaxEntrySize means that the size of the entries is only limited by the cache's MaxMemoryUsage, if any
+const NoMaxEntrySize = 0
+
+// WithMaxEntrySize sets the maximum size of a single entry. Writing an entry larger than that is rejected: TrySet
+// returns an error and leaves the cache unchanged, while Set deletes the key.
+//
+// Regardless of this setting, if the cache has a MaxMemoryUsage or is part of a Budget, entries larger than
+// MaxMemoryUsage, or than the MaxMemoryUsage of the Budget, are always rejected, as the cache would otherwise evict
+// every other entry before evicting the new entry as well.
+//
+// Setting this to NoMaxEntrySize will disable the limit.
+func (cache *Cache) WithMaxEntrySize(maxEntrySizeInBytes int) *Cache {
+	if maxEntrySizeInBytes < 0 {
+		maxEntrySizeInBytes = NoMaxEntrySize
+	}
+	cache.mutex.Lock()
+	cache.maxEntrySize = maxEntrySizeInBytes
+	cache.mutex.Unlock()
+	return cache
+}
+
+// WithMaxEntrySizeRatio sets the maximum size of a single entry as a fraction of the cache's MaxMemoryUsage (e.g. 0.1
+// means that a single entry cannot take more than 10% of MaxMemoryUsage). If both this and WithMaxEntrySize are used,
+// the smallest of the two limits applies. If the cache is part of a Budget whose MaxMemoryUsage is lower than the
+// cache's, or if the cache has no MaxMemoryUsage, the ratio applies to the MaxMemoryUsage of the Budget instead.
+//
+// This has no effect if the cache has no MaxMemoryUsage and isn't part of a Budget. A ratio of 0 or less, or of more
+// than 1, disables the limit.
+func (cache *Cache) WithMaxEntrySizeRatio(ratio float64) *Cache {
+	if ratio <= 0 || ratio > 1 {
+		ratio = 0
+	}
+	cache.mutex.Lock()
+	cache.maxEntrySizeRatio = ratio
+	cache.mutex.Unlock()
+	return cache
+}
+
+// WithAdmissionFunc sets the function used to decide whether a write may take place. If the function returns false,
+// the write is rejected: TrySet returns an error and leaves the cache unchanged, while Set deletes the key.
+//
+// Because it is called for every write while the cache's lock is held, the function must be fast and must not use
+// the cache.
+//
+// Defaults to nil, meaning that every write is admitted.
+func (cache *Cache) WithAdmissionFunc(admit func(key string, value any) bool) *Cache {
+	cache.mutex.Lock()
+	cache.admit = admit
+	cache.mutex.Unlock()
+	return cache
+}
+
+// MaxEntrySize returns the maximum size of a single entry that the cache accepts, taking WithMaxEntrySize,
+// WithMaxEntrySizeRatio, MaxMemoryUsage and the MaxMemoryUsage of the cache's Budget into account, or NoMaxEntrySize
+// if there is no limit
+func (cache *Cache) MaxEntrySize() int {
+	cache.mutex.RLock()
+	defer cache.mutex.RUnlock()
+	return cache.effectiveMaxEntrySize()
+}
+
+// TrySet creates or updates a key with a given value using the default TTL (see Set), but unlike Set, it returns an
+// error if the write was rejected.
+func (cache *Cache) TrySet(key string, value any) error {
+	return cache.TrySetWithTTL(key, value, useDefaultTTL)
+}
+
+// TrySetWithTTL creates or updates a key with a given value and sets an expiration time (-1 is NoExpiration), but
+// unlike SetWithTTL, it returns an error if the write was rejected, in which case the cache is left unchanged:
+//   - ErrInvalidTTL if the TTL is neither greater than 0 nor NoExpiration, rather than deleting the entry
+//   - ErrValueTooLarge if the entry is larger than the maximum entry size (see WithMaxEntrySize), or than the
+//     MaxMemoryUsage of the cache, of its Budget or of the quota it would count toward
+//   - ErrAdmissionRejected if the cost of the entry exceeds the cache's MaxCost, or if the admission function
+//     returned false (see WithAdmissionFunc)
+func (cache *Cache) TrySetWithTTL(key string, value any, ttl time.Duration) error {
+	if ttl != useDefaultTTL && ttl != NoExpiration && ttl < 1 {
+		return ErrInvalidTTL
+	}
+	value = cache.normalizeValue(value)
+	cache.mutex.Lock()
+	defer cache.unlock()
+	_, err := cache.trySet(key, value, ttl, useCostFunc)
+	if err == nil {
+		cache.evictIfNecessary()
+	}
+	return err
+}
+
+// checkAdmission returns an error if writing the key and value passed as parameter with a given size and cost (see
+// measure) must be rejected
+//
+// Must be called while the cache's lock is held.
+func (cache *Cache) checkAdmission(key string, value any, size, cost int) error {
+	if cache.admit != nil && !cache.admit(key, value) {
+		return ErrAdmissionRejected
+	}
+	if cache.maxCost != NoMaxCost && cost > cache.maxCost {
+		return ErrAdmissionRejected
+	}
+	maxEntrySize := cache.effectiveMaxEntrySize()
+	var q *quota
+	if len(cache.quotas) > 0 {
+		if entry, ok := cache.entries[key]; ok {
+			q = entry.quota()
+		} else {
+			q = cache.matchQuota(key)
+		}
+	}
+	if q != nil && q.maxMemoryUsage != NoMaxMemoryUsage && (maxEntrySize == NoMaxEntrySize || q.maxMemoryUsage < maxEntrySize) {
+		maxEntrySize = q.maxMemoryUsage
+	}
+	if maxEntrySize != NoMaxEntrySize && size > maxEntrySize {
+		return ErrValueTooLarge
+	}
+	return nil
+}
+
+// effectiveMaxEntrySize returns the maximum size of a single entry, or NoMaxEntrySize if there is no limit
+//
+// Must be called while the cache's lock is held.
+func (cache *Cache) effectiveMaxEntrySize() int {
+	maxMemoryUsage := cache.maxMemoryUsage
+	if cache.budget != nil && cache.budget.maxMemoryUsage != NoMaxMemoryUsage && (maxMemoryUsage == NoMaxMemoryUsage || cache.budget.maxMemoryUsage < maxMemoryUsage) {
+		// A single entry larger than the Budget would evict every entry of every member
+		maxMemoryUsage = cache.budget.maxMemoryUsage
+	}
+	if maxMemoryUsage == NoMaxMemoryUsage {
+		return cache.maxEntrySize
+	}
+	limit := maxMemoryUsage
+	if cache.maxEntrySizeRatio > 0 {
+		limit = max(int(cache.maxEntrySizeRatio*float64(maxMemoryUsage)), 1)
+	}
+	if cache.maxEntrySize != NoMaxEntrySize && cache.maxEntrySize < limit {
+		limit = cache.maxEntrySize
+	}
+	return limit
+}
@@ -0,0 +1,210 @@
+package gocache
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCache_TrySetWithTTL(t *testing.T) {
+	cache := NewCache()
+	if err := cache.TrySetWithTTL("key", "value", time.Hour); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if err := cache.TrySetWithTTL("key", "new-value", -time.Hour); !errors.Is(err, ErrInvalidTTL) {
+		t.Errorf("expected %v, got %v", ErrInvalidTTL, err)
+	}
+	if err := cache.TrySetWithTTL("key", "new-value", 0); !errors.Is(err, ErrInvalidTTL) {
+		t.Errorf("expected %v, got %v", ErrInvalidTTL, err)
+	}
+	if value, _ := cache.Get("key"); value != "value" {
+		t.Errorf("expected the entry to have been left unchanged, got %v", value)
+	}
+	if err := cache.TrySetWithTTL("key", "new-value", NoExpiration); err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
+	if err := cache.TrySet("other", "value"); err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
+	if cache.Count() != 2 {
+		t.Errorf("expected 2 entries, got %d", cache.Count())
+	}
+}
+
+func TestCache_TrySetWithValueLargerThanMaxMemoryUsage(t *testing.T) {
+	cache := NewCache().WithMaxMemoryUsage(Kilobyte)
+	for _, key := range []string{"1", "2", "3"} {
+		cache.Set(key, "value")
+	}
+	memoryUsage := cache.MemoryUsage()
+	if err := cache.TrySet("large", strings.Repeat("a", Kilobyte)); !errors.Is(err, ErrValueTooLarge) {
+		t.Errorf("expected %v, got %v", ErrValueTooLarge, err)
+	}
+	if err := cache.TrySet("2", strings.Repeat("a", Kilobyte)); !errors.Is(err, ErrValueTooLarge) {
+		t.Errorf("expected %v, got %v", ErrValueTooLarge, err)
+	}
+	if cache.Count() != 3 || cache.MemoryUsage() != memoryUsage {
+		t.Errorf("expected the cache to have been left unchanged, got %d entries", cache.Count())
+	}
+	if value, _ := cache.Get("2"); value != "value" {
+		t.Errorf("expected the existing entry to have been left unchanged, got %v", value)
+	}
+	// Set must not wipe the cache either, but it must not leave the previous value in place
+	cache.Set("large", strings.Repeat("a", Kilobyte))
+	cache.Set("2", strings.Repeat("a", 5*Kilobyte))
+	if _, exists := cache.Get("2"); exists {
+		t.Error("expected the existing entry to have been deleted, because its new value was rejected")
+	}
+	if cache.Count() != 2 {
+		t.Errorf("expected the other entries to have been left unchanged, got %d entries", cache.Count())
+	}
+	if stats := cache.Stats(); stats.EvictedKeys != 0 {
+		t.Errorf("expected no entries to have been evicted, got %d", stats.EvictedKeys)
+	}
+}
+
+func TestCache_WithMaxEntrySize(t *testing.T) {
+	cache := NewCache().WithMaxEntrySize(100)
+	if err := cache.TrySet("small", strings.Repeat("a", 10)); err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
+	if err := cache.TrySet("large", strings.Repeat("a", 100)); !errors.Is(err, ErrValueTooLarge) {
+		t.Errorf("expected %v, got %v", ErrValueTooLarge, err)
+	}
+	if cache.MaxEntrySize() != 100 {
+		t.Errorf("expected max entry size to be 100, got %d", cache.MaxEntrySize())
+	}
+	cache.WithMaxMemoryUsage(50)
+	if cache.MaxEntrySize() != 50 {
+		t.Errorf("expected max entry size to be limited by the max memory usage, got %d", cache.MaxEntrySize())
+	}
+}
+
+func TestCache_WithMaxEntrySizeRatio(t *testing.T) {
+	cache := NewCache().WithMaxMemoryUsage(1000).WithMaxEntrySizeRatio(0.1)
+	if cache.MaxEntrySize() != 100 {
+		t.Errorf("expected max entry size to be 100, got %d", cache.MaxEntrySize())
+	}
+	if err := cache.TrySet("large", strings.Repeat("a", 100)); !errors.Is(err, ErrValueTooLarge) {
+		t.Errorf("expected %v, got %v", ErrValueTooLarge, err)
+	}
+	cache.WithMaxEntrySize(80)
+	if cache.MaxEntrySize() != 80 {
+		t.Errorf("expected the smallest limit to apply, got %d", cache.MaxEntrySize())
+	}
+	cache.WithMaxEntrySizeRatio(2)
+	if cache.MaxEntrySize() != 80 {
+		t.Errorf("expected a ratio greater than 1 to disable the ratio, got %d", cache.MaxEntrySize())
+	}
+}
+
+func TestCache_TrySetWithValueLargerThanQuota(t *testing.T) {
+	cache := NewCache().WithQuota("tenant:*", NoMaxSize, 200)
+	cache.Set("tenant:1", "value")
+	if err := cache.TrySet("tenant:2", strings.Repeat("a", 200)); !errors.Is(err, ErrValueTooLarge) {
+		t.Errorf("expected %v, got %v", ErrValueTooLarge, err)
+	}
+	if err := cache.TrySet("other", strings.Repeat("a", 200)); err != nil {
+		t.Errorf("expected entries that don't count toward the quota not to be limited by it, got %v", err)
+	}
+	if _, exists := cache.Get("tenant:1"); !exists {
+		t.Error("expected tenant:1 not to have been evicted")
+	}
+}
+
+func TestCache_WithAdmissionFunc(t *testing.T) {
+	cache := NewCache().WithAdmissionFunc(func(key string, value any) bool {
+		return !strings.HasPrefix(key, "tmp:")
+	})
+	if err := cache.TrySet("tmp:1", "value"); !errors.Is(err, ErrAdmissionRejected) {
+		t.Errorf("expected %v, got %v", ErrAdmissionRejected, err)
+	}
+	if cache.SetIfAbsent("tmp:2", "value") {
+		t.Error("expected SetIfAbsent to report that the entry was not created")
+	}
+	if err := cache.TrySet("key", "value"); err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
+	if cache.Count() != 1 {
+		t.Errorf("expected 1 entry, got %d", cache.Count())
+	}
+}
+
+func TestCache_WithAdmissionFuncWhenFunctionPanics(t *testing.T) {
+	cache := NewCache().WithAdmissionFunc(func(key string, value any) bool {
+		if strings.HasPrefix(key, "panic:") {
+			panic("oops")
+		}
+		return true
+	})
+	writes := map[string]func(){
+		"SetWithTTL":    func() { cache.SetWithTTL("panic:1", "value", time.Hour) },
+		"TrySetWithTTL": func() { _ = cache.TrySetWithTTL("panic:2", "value", time.Hour) },
+		"SetWithCost":   func() { cache.SetWithCost("panic:3", "value", NoExpiration, 1) },
+		"SetWithTags":   func() { cache.SetWithTags("panic:4", "value", NoExpiration, "tag") },
+		"Increment":     func() { _, _ = cache.Increment("panic:5", 1) },
+	}
+	for name, write := range writes {
+		t.Run(name, func(t *testing.T) {
+			defer func() {
+				if r := recover(); r == nil {
+					t.Error("expected the panic to be propagated to the caller")
+				}
+			}()
+			write()
+		})
+		// If the lock was not released, this would deadlock
+		if cache.Count() != 0 {
+			t.Errorf("expected no entry to have been created by %s, got %d", name, cache.Count())
+		}
+	}
+}
+
+func TestCache_TrySetWithCostLargerThanMaxCost(t *testing.T) {
+	cache := NewCache().WithMaxCost(10).WithCostFunc(func(key string, value any) int {
+		return len(value.(string))
+	})
+	cache.Set("1", "value")
+	if err := cache.TrySet("2", strings.Repeat("a", 11)); !errors.Is(err, ErrAdmissionRejected) {
+		t.Errorf("expected %v, got %v", ErrAdmissionRejected, err)
+	}
+	cache.SetWithCost("3", "value", NoExpiration, 11)
+	if cache.Count() != 1 {
+		t.Errorf("expected entries whose cost exceeds the max cost to be rejected, got %d entries", cache.Count())
+	}
+	cache.SetWithCost("3", strings.Repeat("a", 100), NoExpiration, 5)
+	if cache.Count() != 2 {
+		t.Errorf("expected the cost passed to SetWithCost to take precedence over the cost function, got %d entries", cache.Count())
+	}
+}
+
+func TestCache_SetComputesSizeAndCostOnce(t *testing.T) {
+	sizeFuncCalls, costFuncCalls := 0, 0
+	cache := NewCache().WithMaxMemoryUsage(Kilobyte).WithMaxCost(100).WithQuota("*", NoMaxSize, 512).
+		WithSizeFunc(func(value any) int {
+			sizeFuncCalls++
+			return 10
+		}).
+		WithCostFunc(func(key string, value any) int {
+			costFuncCalls++
+			return 1
+		})
+	cache.Set("key", "value")
+	cache.Set("key", "new value")
+	if sizeFuncCalls != 2 || costFuncCalls != 2 {
+		t.Errorf("expected the size and cost functions to be called once per write, got %d and %d calls", sizeFuncCalls, costFuncCalls)
+	}
+}
+
+func TestCache_SetWithCostLargerThanMaxCost(t *testing.T) {
+	cache := NewCache().WithMaxCost(10)
+	cache.SetWithCost("key", "small", NoExpiration, 1)
+	cache.SetWithCost("key", "large", NoExpiration, 100)
+	if _, exists := cache.Get("key"); exists {
+		t.Error("expected the existing entry to have been deleted, because its new value was rejected")
+	}
+	if cache.Stats().TotalCost != 0 {
+		t.Errorf("expected total cost to be 0, got %d", cache.Stats().TotalCost)
+	}
+}
@@ -0,0 +1,253 @@
+package gocache
+
+import (
+	"errors"
+	"time"
+)
+
+var (
+	ErrVersionConflict = errors.New("entry version does not match the expected version") // Returned when the entry was modified since its version was retrieved
+)
+
+// WithEqualityFunc sets the function used by CompareAndSwap to determine whether the current value of an entry is
+// equal to the expected value.
+//
+// Defaults to reflect.DeepEqual
+func (cache *Cache) WithEqualityFunc(equal func(a, b any) bool) *Cache {
+	cache.equal = equal
+	return cache
+}
+
+// SetIfAbsent creates a key with a given value if the key does not exist, using the default TTL
+//
+// Returns true if the entry was created.
+func (cache *Cache) SetIfAbsent(key string, value any) bool {
+	return cache.SetIfAbsentWithTTL(key, value, useDefaultTTL)
+}
+
+// SetIfAbsentWithTTL creates a key with a given value and expiration time if the key does not exist
+// An expired entry is considered as not existing.
+//
+// Returns true if the entry was created.
+func (cache *Cache) SetIfAbsentWithTTL(key string, value any, ttl time.Duration) bool {
+	value = cache.normalizeValue(value)
+	cache.mutex.Lock()
+	defer cache.unlock()
+	if _, ok := cache.getUnexpired(key); ok {
+		return false
+	}
+	created := cache.set(key, value, ttl) != nil
+	cache.evictIfNecessary()
+	return created
+}
+
+// SetIfPresent updates the value of a key if the key exists, using the default TTL
+//
+// Returns true if the entry was updated.
+func (cache *Cache) SetIfPresent(key string, value any) bool {
+	return cache.SetIfPresentWithTTL(key, value, useDefaultTTL)
+}
+
+// SetIfPresentWithTTL updates the value and the expiration time of a key if the key exists
+// An expired entry is considered as not existing.
+//
+// As with SetWithTTL, passing a TTL of 0 deletes the entry.
+//
+// Returns true if the entry was updated.
+func (cache *Cache) SetIfPresentWithTTL(key string, value any, ttl time.Duration) bool {
+	value = cache.normalizeValue(value)
+	cache.mutex.Lock()
+	defer cache.unlock()
+	if _, ok := cache.getUnexpired(key); !ok {
+		return false
+	}
+	updated := cache.set(key, value, ttl) != nil
+	cache.evictIfNecessary()
+	return updated
+}
+
+// CompareAndSwap updates the value of a key to newValue if and only if the key exists and its current value is
+// equal to oldValue, as determined by the function configured through WithEqualityFunc.
+// Like Set, the entry's expiration time is reset to the default TTL.
+//
+// Returns true if the value was swapped.
+func (cache *Cache) CompareAndSwap(key string, oldValue, newValue any) bool {
+	return cache.compareAndSwap(key, oldValue, newValue, useDefaultTTL)
+}
+
+func (cache *Cache) compareAndSwap(key string, oldValue, newValue any, ttl time.Duration) bool {
+	oldValue, newValue = cache.normalizeValue(oldValue), cache.normalizeValue(newValue)
+	cache.mutex.Lock()
+	defer cache.unlock()
+	entry, ok := cache.getUnexpired(key)
+	if !ok || !cache.equal(entry.Value, oldValue) {
+		return false
+	}
+	swapped := cache.set(key, newValue, ttl) != nil
+	cache.evictIfNecessary()
+	return swapped
+}
+
+// GetAndSet creates or updates a key with a given value using the default TTL, and returns the value that the key
+// had before the update.
+//
+// If the key did not exist, the value returned will be nil and the boolean will be false.
+func (cache *Cache) GetAndSet(key string, value any) (any, bool) {
+	return cache.getAndSet(key, value, useDefaultTTL)
+}
+
+func (cache *Cache) getAndSet(key string, value any, ttl time.Duration) (any, bool) {
+	value = cache.normalizeValue(value)
+	cache.mutex.Lock()
+	defer cache.unlock()
+	var oldValue any
+	entry, ok := cache.getUnexpired(key)
+	if ok {
+		oldValue = entry.Value
+		cache.stats.Hits++
+	} else {
+		cache.stats.Misses++
+	}
+	cache.set(key, value, ttl)
+	cache.evictIfNecessary()
+	return oldValue, ok
+}
+
+// GetAndDelete removes a key from the cache and returns the value it had.
+//
+// If the key did not exist, the value returned will be nil and the boolean will be false.
+func (cache *Cache) GetAndDelete(key string) (any, bool) {
+	cache.mutex.Lock()
+	entry, ok := cache.getUnexpired(key)
+	if !ok {
+		cache.stats.Misses++
+		cache.unlock()
+		return nil, false
+	}
+	cache.stats.Hits++
+	cache.remove(entry, Deleted)
+	cache.unlock()
+	return entry.Value, true
+}
+
+// Update atomically updates the value of a key using the function passed as parameter, which is called with the
+// current value of the key as well as whether the key exists (an expired entry is considered as not existing).
+//
+// The function returns the new value, the TTL to use (as with SetWithTTL, NoExpiration means that the entry never
+// expires) and whether the entry should be kept. If keep is false, the entry is deleted if it exists.
+//
+// Because the function is called while the cache's lock is held, no other operation can happen on the cache until
+// the function returns. This also means that the function must NOT call any of the cache's functions, otherwise
+// it will cause a deadlock.
+//
+// Returns the new value of the key and whether the key exists after the update. If the new value is rejected (see
+// TrySetWithTTL), the key is deleted, like with SetWithTTL.
+//
+//	cache.Update("visitors", func(old any, exists bool) (any, time.Duration, bool) {
+//		if !exists {
+//			return []string{"john"}, time.Hour, true
+//		}
+//		return append(old.([]string), "john"), time.Hour, true
+//	})
+func (cache *Cache) Update(key string, fn func(old any, exists bool) (newValue any, ttl time.Duration, keep bool)) (any, bool) {
+	value, exists, _ := cache.update(key, fn, true)
+	return value, exists
+}
+
+// TryUpdate atomically updates the value of a key using the function passed as parameter (see Update), but unlike
+// Update, it returns an error if the new value was rejected (see TrySetWithTTL), in which case the cache is left
+// unchanged, and the current value of the key is returned along with whether the key exists.
+func (cache *Cache) TryUpdate(key string, fn func(old any, exists bool) (newValue any, ttl time.Duration, keep bool)) (any, bool, error) {
+	return cache.update(key, fn, false)
+}
+
+// update atomically updates the value of a key using the function passed as parameter, and deletes the key if the
+// new value is rejected and deleteIfRejected is true
+func (cache *Cache) update(key string, fn func(old any, exists bool) (any, time.Duration, bool), deleteIfRejected bool) (any, bool, error) {
+	cache.mutex.Lock()
+	// The lock must be released even if fn panics
+	defer cache.unlock()
+	var oldValue any
+	entry, exists := cache.getUnexpired(key)
+	if exists {
+		oldValue = entry.Value
+	}
+	newValue, ttl, keep := fn(oldValue, exists)
+	if !keep {
+		if exists {
+			cache.remove(entry, Deleted)
+		}
+		return nil, false, nil
+	}
+	newValue = cache.normalizeValue(newValue)
+	entry, err := cache.trySet(key, newValue, ttl, useCostFunc)
+	if err != nil {
+		if deleteIfRejected {
+			cache.delete(key)
+			return nil, false, err
+		}
+		return oldValue, exists, err
+	}
+	if entry == nil {
+		return nil, false, nil
+	}
+	cache.evictIfNecessary()
+	// The entry that was just updated may have been evicted if its new value is too large
+	_, exists = cache.get(key)
+	return newValue, exists, nil
+}
+
+// GetWithVersion retrieves an entry using the key passed as parameter, along with the entry's current Version.
+//
+// Like Get, this counts as accessing the entry. If there is no such entry, the value returned will be nil, the
+// version will be 0 and the boolean will be false.
+func (cache *Cache) GetWithVersion(key string) (any, uint64, bool) {
+	cache.mutex.Lock()
+	entry, ok := cache.access(key)
+	if !ok {
+		cache.unlock()
+		return nil, 0, false
+	}
+	value, version := entry.Value, entry.Version
+	cache.unlock()
+	return value, version, true
+}
+
+// SetIfVersion creates or updates a key with a given value and expiration time only if the entry's current Version
+// is equal to expectedVersion, which allows implementing optimistic concurrency: retrieve the value and its version
+// using GetWithVersion, do some work without holding any lock, and then write the result back with SetIfVersion.
+// If ErrVersionConflict is returned, another goroutine modified the entry in the meantime, and the operation
+// should be retried.
+//
+// An expectedVersion of 0 means that the key must not exist (an expired entry is considered as not existing).
+//
+// Returns ErrVersionConflict if the entry's version does not match the expected version.
+func (cache *Cache) SetIfVersion(key string, value any, ttl time.Duration, expectedVersion uint64) error {
+	value = cache.normalizeValue(value)
+	cache.mutex.Lock()
+	defer cache.unlock()
+	var currentVersion uint64
+	if entry, ok := cache.getUnexpired(key); ok {
+		currentVersion = entry.Version
+	}
+	if currentVersion != expectedVersion {
+		return ErrVersionConflict
+	}
+	cache.set(key, value, ttl)
+	cache.evictIfNecessary()
+	return nil
+}
+
+// getUnexpired retrieves an entry using the key passed as parameter, but unlike get, it deletes the entry and
+// returns false if the entry has expired
+//
+// Must be called while the cache's lock is held.
+func (cache *Cache) getUnexpired(key string) (*Entry, bool) {
+	entry, ok := cache.get(key)
+	if ok && entry.Expired() {
+		cache.stats.ExpiredKeys++
+		cache.remove(entry, Expired)
+		return nil, false
+	}
+	return entry, ok
+}
@@ -0,0 +1,427 @@
+package gocache
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestCache_SetIfAbsent(t *testing.T) {
+	cache := NewCache()
+	if !cache.SetIfAbsent("key", "value") {
+		t.Error("expected SetIfAbsent to return true, because the key did not exist")
+	}
+	if cache.SetIfAbsent("key", "new-value") {
+		t.Error("expected SetIfAbsent to return false, because the key already exists")
+	}
+	if value := cache.GetValue("key"); value != "value" {
+		t.Errorf("expected %s, got %v", "value", value)
+	}
+	cache.SetWithTTL("expired", "old-value", time.Nanosecond)
+	time.Sleep(time.Millisecond)
+	if !cache.SetIfAbsentWithTTL("expired", "new-value", time.Hour) {
+		t.Error("expected SetIfAbsentWithTTL to return true, because the key has expired")
+	}
+	if ttl, err := cache.TTL("expired"); err != nil || ttl < 59*time.Minute {
+		t.Errorf("expected TTL to be almost an hour, got %s (err=%v)", ttl, err)
+	}
+	if cache.SetIfAbsentWithTTL("zero-ttl", "value", 0) {
+		t.Error("expected SetIfAbsentWithTTL to return false, because the TTL is 0")
+	}
+}
+
+func TestCache_SetIfAbsentIsAtomic(t *testing.T) {
+	cache := NewCache()
+	var wg sync.WaitGroup
+	var mutex sync.Mutex
+	numberOfSuccesses := 0
+	for i := 0; i < 100; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			if cache.SetIfAbsent("key", i) {
+				mutex.Lock()
+				numberOfSuccesses++
+				mutex.Unlock()
+			}
+		}(i)
+	}
+	wg.Wait()
+	if numberOfSuccesses != 1 {
+		t.Errorf("expected exactly 1 goroutine to create the key, got %d", numberOfSuccesses)
+	}
+}
+
+func TestCache_SetIfPresent(t *testing.T) {
+	cache := NewCache()
+	if cache.SetIfPresent("key", "value") {
+		t.Error("expected SetIfPresent to return false, because the key does not exist")
+	}
+	if _, exists := cache.Get("key"); exists {
+		t.Error("expected key to not exist")
+	}
+	cache.Set("key", "value")
+	if !cache.SetIfPresent("key", "new-value") {
+		t.Error("expected SetIfPresent to return true, because the key exists")
+	}
+	if value := cache.GetValue("key"); value != "new-value" {
+		t.Errorf("expected %s, got %v", "new-value", value)
+	}
+	if cache.SetIfPresentWithTTL("key", "newer-value", 0) {
+		t.Error("expected SetIfPresentWithTTL to return false, because a TTL of 0 deletes the key")
+	}
+	if _, exists := cache.Get("key"); exists {
+		t.Error("expected key to have been deleted")
+	}
+}
+
+func TestCache_CompareAndSwap(t *testing.T) {
+	cache := NewCache()
+	if cache.CompareAndSwap("key", nil, "value") {
+		t.Error("expected CompareAndSwap to return false, because the key does not exist")
+	}
+	cache.Set("key", []string{"a"})
+	if cache.CompareAndSwap("key", []string{"b"}, []string{"c"}) {
+		t.Error("expected CompareAndSwap to return false, because the old value doesn't match")
+	}
+	if !cache.CompareAndSwap("key", []string{"a"}, []string{"c"}) {
+		t.Error("expected CompareAndSwap to return true, because the old value matches")
+	}
+	if value := cache.GetValue("key"); value.([]string)[0] != "c" {
+		t.Errorf("expected %v, got %v", []string{"c"}, value)
+	}
+}
+
+func TestCache_CompareAndSwapWithNilPointer(t *testing.T) {
+	type Struct struct{}
+	cache := NewCache()
+	cache.Set("key", (*Struct)(nil))
+	if !cache.CompareAndSwap("key", (*Struct)(nil), "value") {
+		t.Error("expected CompareAndSwap to return true, because the nil pointer should've been treated as nil")
+	}
+}
+
+func TestCache_CompareAndSwapWithEqualityFunc(t *testing.T) {
+	type Struct struct{ ID, Name string }
+	cache := NewCache().WithEqualityFunc(func(a, b any) bool {
+		return a.(Struct).ID == b.(Struct).ID
+	})
+	cache.Set("key", Struct{ID: "1", Name: "john"})
+	if !cache.CompareAndSwap("key", Struct{ID: "1"}, Struct{ID: "1", Name: "jane"}) {
+		t.Error("expected CompareAndSwap to return true, because the equality func only compares IDs")
+	}
+	if value := cache.GetValue("key"); value.(Struct).Name != "jane" {
+		t.Errorf("expected name to be jane, got %v", value)
+	}
+}
+
+func TestCache_CompareAndSwapIsAtomic(t *testing.T) {
+	cache := NewCache()
+	cache.Set("counter", 0)
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				value := cache.GetValue("counter").(int)
+				if cache.CompareAndSwap("counter", value, value+1) {
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	if value := cache.GetValue("counter"); value != 50 {
+		t.Errorf("expected counter to be 50, got %v", value)
+	}
+}
+
+func TestCache_GetAndSet(t *testing.T) {
+	cache := NewCache().WithMaxSize(1)
+	oldValue, existed := cache.GetAndSet("key", "value")
+	if existed || oldValue != nil {
+		t.Errorf("expected key to not have existed, got %v", oldValue)
+	}
+	oldValue, existed = cache.GetAndSet("key", "new-value")
+	if !existed || oldValue != "value" {
+		t.Errorf("expected old value to be %s, got %v", "value", oldValue)
+	}
+	cache.GetAndSet("other-key", "value")
+	if _, exists := cache.Get("key"); exists {
+		t.Error("expected key to have been evicted, because the max size is 1")
+	}
+	if stats := cache.Stats(); stats.Hits != 1 || stats.Misses != 3 {
+		t.Errorf("expected 1 hit and 3 misses, got %d hits and %d misses", stats.Hits, stats.Misses)
+	}
+}
+
+func TestCache_GetAndDelete(t *testing.T) {
+	cache := NewCache().WithMaxMemoryUsage(Kilobyte)
+	cache.Set("key", "value")
+	value, existed := cache.GetAndDelete("key")
+	if !existed || value != "value" {
+		t.Errorf("expected value to be %s, got %v", "value", value)
+	}
+	if cache.Count() != 0 || cache.MemoryUsage() != 0 {
+		t.Error("expected cache to be empty")
+	}
+	if _, existed = cache.GetAndDelete("key"); existed {
+		t.Error("expected key to no longer exist")
+	}
+	cache.SetWithTTL("expired", "value", time.Nanosecond)
+	time.Sleep(time.Millisecond)
+	if _, existed = cache.GetAndDelete("expired"); existed {
+		t.Error("expected key to be considered as not existing, because it has expired")
+	}
+	if cache.Stats().ExpiredKeys != 1 {
+		t.Error("expected 1 expired key")
+	}
+}
+
+func TestCache_GetAndDeleteIsAtomic(t *testing.T) {
+	cache := NewCache()
+	for i := 0; i < 100; i++ {
+		cache.Set(fmt.Sprintf("%d", i), i)
+	}
+	var wg sync.WaitGroup
+	var mutex sync.Mutex
+	numberOfValuesReceived := 0
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 100; i++ {
+				if _, ok := cache.GetAndDelete(fmt.Sprintf("%d", i)); ok {
+					mutex.Lock()
+					numberOfValuesReceived++
+					mutex.Unlock()
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	if numberOfValuesReceived != 100 {
+		t.Errorf("expected each value to be received exactly once, got %d values", numberOfValuesReceived)
+	}
+}
+
+func TestCache_Update(t *testing.T) {
+	cache := NewCache()
+	appendName := func(name string) func(old any, exists bool) (any, time.Duration, bool) {
+		return func(old any, exists bool) (any, time.Duration, bool) {
+			if !exists {
+				return []string{name}, time.Hour, true
+			}
+			return append(old.([]string), name), time.Hour, true
+		}
+	}
+	value, exists := cache.Update("names", appendName("john"))
+	if !exists || len(value.([]string)) != 1 {
+		t.Errorf("expected [john], got %v", value)
+	}
+	value, exists = cache.Update("names", appendName("jane"))
+	if !exists || len(value.([]string)) != 2 || value.([]string)[1] != "jane" {
+		t.Errorf("expected [john jane], got %v", value)
+	}
+	if ttl, err := cache.TTL("names"); err != nil || ttl < 59*time.Minute {
+		t.Errorf("expected TTL to be almost an hour, got %s (err=%v)", ttl, err)
+	}
+	value, exists = cache.Update("names", func(old any, exists bool) (any, time.Duration, bool) {
+		return nil, 0, false
+	})
+	if exists || value != nil {
+		t.Errorf("expected key to have been deleted, got %v", value)
+	}
+	if _, exists = cache.Get("names"); exists {
+		t.Error("expected key to have been deleted")
+	}
+}
+
+func TestCache_UpdateIsAtomic(t *testing.T) {
+	cache := NewCache()
+	var wg sync.WaitGroup
+	for i := 0; i < 100; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			cache.Update("counter", func(old any, exists bool) (any, time.Duration, bool) {
+				if !exists {
+					return 1, NoExpiration, true
+				}
+				return old.(int) + 1, NoExpiration, true
+			})
+		}()
+	}
+	wg.Wait()
+	if value := cache.GetValue("counter"); value != 100 {
+		t.Errorf("expected counter to be 100, got %v", value)
+	}
+}
+
+func TestCache_UpdateWhenFunctionPanics(t *testing.T) {
+	cache := NewCache()
+	cache.Set("key", "value")
+	func() {
+		defer func() {
+			if r := recover(); r == nil {
+				t.Error("expected the panic to be propagated to the caller")
+			}
+		}()
+		cache.Update("key", func(old any, exists bool) (any, time.Duration, bool) {
+			panic("oops")
+		})
+	}()
+	// If the lock was not released, this would deadlock
+	if value := cache.GetValue("key"); value != "value" {
+		t.Errorf("expected value to be left unchanged, got %v", value)
+	}
+}
+
+func TestCache_UpdateWithMaxMemoryUsage(t *testing.T) {
+	cache := NewCache().WithMaxSize(NoMaxSize).WithMaxMemoryUsage(Kilobyte).WithEvictionPolicy(LeastRecentlyUsed)
+	cache.Set("1", "a")
+	cache.Set("2", "b")
+	memoryUsageBeforeUpdate := cache.MemoryUsage()
+	// Growing the value of 1 should move it to the head and evict 2
+	cache.Update("1", func(old any, exists bool) (any, time.Duration, bool) {
+		return old.(string) + strings.Repeat("a", 900), NoExpiration, true
+	})
+	if cache.MemoryUsage() <= memoryUsageBeforeUpdate {
+		t.Error("expected memory usage to have increased")
+	}
+	if cache.head.Key != "1" {
+		t.Error("expected the updated entry to have been moved to the head")
+	}
+	if _, exists := cache.Get("2"); exists {
+		t.Error("expected 2 to have been evicted to make room for the larger value of 1")
+	}
+	cache.Update("1", func(old any, exists bool) (any, time.Duration, bool) {
+		return nil, 0, false
+	})
+	if cache.MemoryUsage() != 0 {
+		t.Errorf("expected memory usage to be 0, got %d", cache.MemoryUsage())
+	}
+}
+
+func TestCache_TryUpdate(t *testing.T) {
+	cache := NewCache().WithMaxEntrySize(100)
+	cache.Set("key", "value")
+	value, exists, err := cache.TryUpdate("key", func(old any, exists bool) (any, time.Duration, bool) {
+		return strings.Repeat("a", 200), NoExpiration, true
+	})
+	if err != ErrValueTooLarge {
+		t.Errorf("expected %v, got %v", ErrValueTooLarge, err)
+	}
+	if value != "value" || !exists || cache.GetValue("key") != "value" {
+		t.Errorf("expected the value to have been left unchanged, got %v", value)
+	}
+	// Update deletes the key instead
+	if value, exists = cache.Update("key", func(old any, exists bool) (any, time.Duration, bool) {
+		return strings.Repeat("a", 200), NoExpiration, true
+	}); value != nil || exists {
+		t.Errorf("expected the key to have been deleted, got %v", value)
+	}
+	if _, exists = cache.Get("key"); exists {
+		t.Error("expected the existing entry to have been deleted, because its new value was rejected")
+	}
+	if value, exists, err = cache.TryUpdate("key", func(old any, exists bool) (any, time.Duration, bool) {
+		return "new value", NoExpiration, true
+	}); err != nil || value != "new value" || !exists {
+		t.Errorf("expected the key to have been created, got %v (err=%v)", value, err)
+	}
+}
+
+func TestCache_GetWithVersion(t *testing.T) {
+	cache := NewCache()
+	if _, version, exists := cache.GetWithVersion("key"); exists || version != 0 {
+		t.Errorf("expected key to not exist and version to be 0, got %d", version)
+	}
+	cache.Set("key", "v1")
+	value, version, exists := cache.GetWithVersion("key")
+	if !exists || value != "v1" || version == 0 {
+		t.Errorf("expected value v1 with a non-zero version, got %v with version %d", value, version)
+	}
+	cache.Set("key", "v2")
+	if _, newVersion, _ := cache.GetWithVersion("key"); newVersion <= version {
+		t.Errorf("expected version to have been incremented, got %d then %d", version, newVersion)
+	}
+	if cache.Stats().Hits != 2 || cache.Stats().Misses != 1 {
+		t.Error("expected GetWithVersion to count as accessing the entry")
+	}
+}
+
+func TestCache_SetIfVersion(t *testing.T) {
+	cache := NewCache()
+	if err := cache.SetIfVersion("key", "v1", NoExpiration, 1); err != ErrVersionConflict {
+		t.Errorf("expected %v, because the key does not exist, got %v", ErrVersionConflict, err)
+	}
+	if err := cache.SetIfVersion("key", "v1", NoExpiration, 0); err != nil {
+		t.Errorf("expected no error, because a version of 0 means that the key must not exist, got %v", err)
+	}
+	_, version, _ := cache.GetWithVersion("key")
+	// Another goroutine modifies the entry
+	cache.Set("key", "v2")
+	if err := cache.SetIfVersion("key", "v3", NoExpiration, version); err != ErrVersionConflict {
+		t.Errorf("expected %v, got %v", ErrVersionConflict, err)
+	}
+	if value := cache.GetValue("key"); value != "v2" {
+		t.Errorf("expected value to be left unchanged, got %v", value)
+	}
+	_, version, _ = cache.GetWithVersion("key")
+	if err := cache.SetIfVersion("key", "v3", time.Hour, version); err != nil {
+		t.Error("expected no error, got", err)
+	}
+	if value := cache.GetValue("key"); value != "v3" {
+		t.Errorf("expected %s, got %v", "v3", value)
+	}
+}
+
+func TestCache_SetIfVersionWhenKeyIsRecreated(t *testing.T) {
+	cache := NewCache()
+	cache.Set("key", "v1")
+	_, version, _ := cache.GetWithVersion("key")
+	cache.Delete("key")
+	cache.Set("key", "v2")
+	if err := cache.SetIfVersion("key", "v3", NoExpiration, version); err != ErrVersionConflict {
+		t.Errorf("expected %v, because the key was deleted and created again, got %v", ErrVersionConflict, err)
+	}
+}
+
+func TestCache_SetIfVersionAfterExpire(t *testing.T) {
+	cache := NewCache()
+	cache.Set("key", "v1")
+	_, version, _ := cache.GetWithVersion("key")
+	// Another goroutine changes the expiration time of the entry
+	cache.Expire("key", time.Hour)
+	if err := cache.SetIfVersion("key", "v2", NoExpiration, version); err != ErrVersionConflict {
+		t.Errorf("expected %v, because the expiration time of the entry was changed, got %v", ErrVersionConflict, err)
+	}
+	if _, newVersion, _ := cache.GetWithVersion("key"); newVersion <= version {
+		t.Errorf("expected version to be greater than %d, got %d", version, newVersion)
+	}
+}
+
+func TestCache_SetIfVersionIsAtomic(t *testing.T) {
+	cache := NewCache()
+	cache.Set("counter", 0)
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				value, version, _ := cache.GetWithVersion("counter")
+				if cache.SetIfVersion("counter", value.(int)+1, NoExpiration, version) == nil {
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	if value := cache.GetValue("counter"); value != 50 {
+		t.Errorf("expected counter to be 50, got %v", value)
+	}
+}
@@ -0,0 +1,227 @@
+package gocache
+
+import (
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// BudgetPolicy is what dictates which member of a Budget entries are evicted from when the Budget is exceeded
+type BudgetPolicy string
+
+const (
+	// ColdestTail is a budget policy that causes entries to be evicted from the member whose tail has the oldest
+	// RelevantTimestamp, which means that the entries of the different members are evicted in roughly the same order
+	// as if they were all part of a single cache.
+	ColdestTail BudgetPolicy = "ColdestTail"
+
+	// Weighted is a budget policy that causes entries to be evicted from the member whose memory usage is the highest
+	// relative to its weight, which means that the memory of the Budget ends up being shared by the members in
+	// proportion to their weight.
+	//
+	// For instance, if a member has a weight of 3 and another has a weight of 1, the first one may use up to 3 times as
+	// much memory as the second one before entries are evicted from it instead of the second one.
+	Weighted BudgetPolicy = "Weighted"
+)
+
+// Budget is a memory limit shared by multiple caches, which prevents memory from being stranded in idle caches while
+// busy ones are constantly evicting entries because of their own MaxMemoryUsage.
+//
+// Whenever the combined memory usage of the caches that joined the Budget (see Cache.WithBudget) exceeds the Budget's
+// MaxMemoryUsage, entries are evicted from the members based on the Budget's policy until it no longer does. Each
+// member's own MaxSize, MaxMemoryUsage and quotas still apply.
+//
+// Do not instantiate this struct directly, use NewBudget instead
+type Budget struct {
+	// maxMemoryUsage is the maximum amount of memory that can be used by the members combined
+	maxMemoryUsage int
+
+	// policy is the policy used to pick the member that entries are evicted from
+	policy BudgetPolicy
+
+	// memoryUsage is the combined memory usage of the members, which is updated by the members while their own lock
+	// is held, and therefore does not require the Budget's lock
+	memoryUsage atomic.Int64
+
+	// mutex is the lock of the Budget, which must always be acquired before the lock of any of the members, and which
+	// must never be held while the onEvicted callback of a member is called, or while its events are published
+	mutex sync.Mutex
+
+	// members are the caches that joined the Budget, in the order in which they joined
+	members []*budgetMember
+}
+
+// budgetMember is a cache that joined a Budget
+type budgetMember struct {
+	cache *Cache
+
+	// weight is the weight of the cache, which is used by the Weighted policy
+	weight int
+}
+
+// NewBudget creates a new Budget with a given maximum memory usage, which uses the ColdestTail policy by default
+//
+//	budget := gocache.NewBudget(512 * gocache.Megabyte)
+//	users := gocache.NewCache().WithMaxSize(gocache.NoMaxSize).WithBudget(budget, 1)
+//	products := gocache.NewCache().WithMaxSize(gocache.NoMaxSize).WithBudget(budget, 1)
+func NewBudget(maxMemoryUsageInBytes int) *Budget {
+	if maxMemoryUsageInBytes < 0 {
+		maxMemoryUsageInBytes = NoMaxMemoryUsage
+	}
+	return &Budget{
+		maxMemoryUsage: maxMemoryUsageInBytes,
+		policy:         ColdestTail,
+	}
+}
+
+// WithPolicy sets the policy used to pick the member that entries are evicted from when the Budget is exceeded
+//
+// Defaults to ColdestTail
+func (budget *Budget) WithPolicy(policy BudgetPolicy) *Budget {
+	budget.mutex.Lock()
+	budget.policy = policy
+	budget.mutex.Unlock()
+	return budget
+}
+
+// MaxMemoryUsage returns the maximum amount of memory that can be used by the members of the Budget combined
+func (budget *Budget) MaxMemoryUsage() int {
+	return budget.maxMemoryUsage
+}
+
+// MemoryUsage returns the combined memory usage of the members of the Budget in bytes
+func (budget *Budget) MemoryUsage() int {
+	return int(budget.memoryUsage.Load())
+}
+
+// WithBudget makes the cache join a Budget with a given weight, which is only used by the Weighted policy. A weight
+// of 0 or less is treated as 1.
+//
+// If the cache was already part of another Budget, it leaves it first. Passing a nil Budget makes the cache leave its
+// current Budget, if any.
+func (cache *Cache) WithBudget(budget *Budget, weight int) *Cache {
+	cache.mutex.RLock()
+	previous := cache.budget
+	cache.mutex.RUnlock()
+	if previous != nil {
+		previous.remove(cache)
+	}
+	if budget != nil {
+		budget.add(cache, max(weight, 1))
+		budget.enforce()
+	}
+	return cache
+}
+
+// Budget returns the Budget the cache is part of, or nil if it isn't part of any
+func (cache *Cache) Budget() *Budget {
+	cache.mutex.RLock()
+	defer cache.mutex.RUnlock()
+	return cache.budget
+}
+
+// add makes a cache join the Budget, and adds its memory usage to the Budget's
+func (budget *Budget) add(cache *Cache, weight int) {
+	budget.mutex.Lock()
+	cache.mutex.Lock()
+	cache.budget = budget
+	budget.members = append(budget.members, &budgetMember{cache: cache, weight: weight})
+	budget.memoryUsage.Add(int64(cache.memoryUsage))
+	if len(cache.entries) > 0 {
+		// The size of the existing entries may not have been tracked until now
+		cache.recalculateMemoryUsage()
+	}
+	n := cache.takeNotifications()
+	cache.mutex.Unlock()
+	budget.mutex.Unlock()
+	n.deliver()
+}
+
+// remove makes a cache leave the Budget, and removes its memory usage from the Budget's
+func (budget *Budget) remove(cache *Cache) {
+	budget.mutex.Lock()
+	cache.mutex.Lock()
+	if cache.budget == budget {
+		budget.memoryUsage.Add(-int64(cache.memoryUsage))
+		cache.budget = nil
+		budget.members = slices.DeleteFunc(budget.members, func(member *budgetMember) bool {
+			return member.cache == cache
+		})
+		if len(cache.entries) > 0 {
+			// The memory usage of the cache may no longer need to be tracked
+			cache.recalculateMemoryUsage()
+		}
+	}
+	n := cache.takeNotifications()
+	cache.mutex.Unlock()
+	budget.mutex.Unlock()
+	n.deliver()
+}
+
+// exceeded returns whether the combined memory usage of the members exceeds the Budget's maxMemoryUsage
+func (budget *Budget) exceeded() bool {
+	return budget.maxMemoryUsage != NoMaxMemoryUsage && budget.memoryUsage.Load() > int64(budget.maxMemoryUsage)
+}
+
+// enforce evicts entries from the members until the Budget is no longer exceeded, and then passes the evicted entries
+// to the onEvicted callback of their cache and publishes the corresponding events, which only happens once the
+// Budget's lock has been released so that the callbacks and the subscribers may use the members
+//
+// Must be called while neither the Budget's lock nor the lock of any of the members is held.
+func (budget *Budget) enforce() {
+	if !budget.exceeded() {
+		return
+	}
+	var pending []notifications
+	budget.mutex.Lock()
+	// Members that have no entry left that can be evicted
+	var exhausted []*Cache
+	for budget.exceeded() {
+		victim := budget.pickVictim(exhausted)
+		if victim == nil {
+			break
+		}
+		victim.mutex.Lock()
+		if !victim.evict() {
+			exhausted = append(exhausted, victim)
+		}
+		pending = append(pending, victim.takeNotifications())
+		victim.mutex.Unlock()
+	}
+	budget.mutex.Unlock()
+	for _, n := range pending {
+		n.deliver()
+	}
+}
+
+// pickVictim returns the member that the next entry must be evicted from based on the Budget's policy, ignoring the
+// members passed as parameter, or nil if no member has entries
+//
+// Must be called while the Budget's lock is held.
+func (budget *Budget) pickVictim(exhausted []*Cache) *Cache {
+	var victim *Cache
+	var coldest time.Time
+	var highestUsagePerWeight float64
+	for _, member := range budget.members {
+		if slices.Contains(exhausted, member.cache) {
+			continue
+		}
+		member.cache.mutex.RLock()
+		if tail := member.cache.tail; tail != nil {
+			switch budget.policy {
+			case Weighted:
+				usagePerWeight := float64(member.cache.memoryUsage) / float64(member.weight)
+				if victim == nil || usagePerWeight > highestUsagePerWeight {
+					victim, highestUsagePerWeight = member.cache, usagePerWeight
+				}
+			default:
+				if victim == nil || tail.RelevantTimestamp.Before(coldest) {
+					victim, coldest = member.cache, tail.RelevantTimestamp
+				}
+			}
+		}
+		member.cache.mutex.RUnlock()
+	}
+	return victim
+}
@@ -0,0 +1,160 @@
+package gocache
+
+import (
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestBudget_ColdestTail(t *testing.T) {
+	budget := NewBudget(10 * Kilobyte)
+	idle := NewCache().WithMaxSize(NoMaxSize).WithBudget(budget, 1)
+	busy := NewCache().WithMaxSize(NoMaxSize).WithBudget(budget, 1)
+	for i := 0; i < 5; i++ {
+		idle.Set(strconv.Itoa(i), make([]byte, 1000))
+	}
+	time.Sleep(time.Millisecond)
+	for i := 0; i < 20; i++ {
+		busy.Set(strconv.Itoa(i), make([]byte, 1000))
+	}
+	if budget.MemoryUsage() > budget.MaxMemoryUsage() {
+		t.Errorf("expected memory usage to be at most %d, got %d", budget.MaxMemoryUsage(), budget.MemoryUsage())
+	}
+	if budget.MemoryUsage() != idle.MemoryUsage()+busy.MemoryUsage() {
+		t.Errorf("expected memory usage of the budget to be the sum of the memory usage of its members, got %d", budget.MemoryUsage())
+	}
+	// The entries of the idle cache are older than every entry of the busy cache, so they must be evicted first
+	if idle.Count() != 0 {
+		t.Errorf("expected every entry of the idle cache to have been evicted, got %d entries", idle.Count())
+	}
+	if busy.Count() == 0 || busy.Stats().EvictedKeys == 0 {
+		t.Errorf("expected entries of the busy cache to have been evicted once the idle cache was empty, got %d entries", busy.Count())
+	}
+	if _, exists := busy.Get("19"); !exists {
+		t.Error("expected the most recent entry not to have been evicted")
+	}
+}
+
+func TestBudget_Weighted(t *testing.T) {
+	budget := NewBudget(20 * Kilobyte).WithPolicy(Weighted)
+	heavy := NewCache().WithMaxSize(NoMaxSize).WithBudget(budget, 3)
+	light := NewCache().WithMaxSize(NoMaxSize).WithBudget(budget, 1)
+	for i := 0; i < 100; i++ {
+		heavy.Set(strconv.Itoa(i), make([]byte, 100))
+		light.Set(strconv.Itoa(i), make([]byte, 100))
+	}
+	if budget.MemoryUsage() > budget.MaxMemoryUsage() {
+		t.Errorf("expected memory usage to be at most %d, got %d", budget.MaxMemoryUsage(), budget.MemoryUsage())
+	}
+	// The memory must be shared in proportion to the weights
+	if ratio := float64(heavy.MemoryUsage()) / float64(light.MemoryUsage()); ratio < 2.5 || ratio > 3.5 {
+		t.Errorf("expected the heavy cache to use about 3 times as much memory as the light cache, got %d and %d", heavy.MemoryUsage(), light.MemoryUsage())
+	}
+}
+
+func TestCache_WithBudget(t *testing.T) {
+	budget := NewBudget(10 * Kilobyte)
+	cache := NewCache()
+	cache.Set("1", make([]byte, 6000))
+	cache.Set("2", make([]byte, 6000))
+	cache.WithBudget(budget, 0)
+	if cache.Budget() != budget {
+		t.Error("expected the cache to be part of the budget")
+	}
+	if cache.Count() != 1 || budget.MemoryUsage() != cache.MemoryUsage() || cache.MemoryUsage() == 0 {
+		t.Errorf("expected entries to have been evicted when the cache joined the budget, got %d entries", cache.Count())
+	}
+	other := NewBudget(NoMaxMemoryUsage)
+	cache.WithBudget(other, 1)
+	if budget.MemoryUsage() != 0 || other.MemoryUsage() != cache.MemoryUsage() {
+		t.Errorf("expected the memory usage of the cache to have moved to the other budget, got %d and %d", budget.MemoryUsage(), other.MemoryUsage())
+	}
+	cache.WithBudget(nil, 0)
+	if cache.Budget() != nil || other.MemoryUsage() != 0 || cache.MemoryUsage() != 0 {
+		t.Errorf("expected the cache to have left the budget, got a memory usage of %d", other.MemoryUsage())
+	}
+	cache.Set("3", make([]byte, 6000))
+	if cache.Count() != 2 {
+		t.Errorf("expected the budget not to apply anymore, got %d entries", cache.Count())
+	}
+}
+
+func TestBudget_Concurrency(t *testing.T) {
+	budget := NewBudget(50 * Kilobyte)
+	caches := make([]*Cache, 5)
+	for i := range caches {
+		caches[i] = NewCache().WithMaxSize(NoMaxSize).WithEvictionPolicy(LeastRecentlyUsed).WithBudget(budget, i+1)
+	}
+	// Writing to another member from the onEvicted callback must not cause a deadlock
+	caches[0].WithOnEvicted(func(key string, value any, reason RemovalReason) {
+		caches[1].Set("evicted:"+key, "value")
+	})
+	var wg sync.WaitGroup
+	for i := range caches {
+		wg.Add(1)
+		go func(cache *Cache) {
+			defer wg.Done()
+			for j := 0; j < 2000; j++ {
+				cache.Set(strconv.Itoa(j%300), make([]byte, 100))
+				cache.Get(strconv.Itoa(j % 100))
+			}
+		}(caches[i])
+	}
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 100; i++ {
+			budget.WithPolicy(Weighted)
+			budget.WithPolicy(ColdestTail)
+		}
+	}()
+	wg.Wait()
+	memoryUsage := 0
+	for _, cache := range caches {
+		memoryUsage += cache.MemoryUsage()
+	}
+	if memoryUsage != budget.MemoryUsage() {
+		t.Errorf("expected memory usage of the budget to be %d, got %d", memoryUsage, budget.MemoryUsage())
+	}
+	if budget.MemoryUsage() > budget.MaxMemoryUsage() {
+		t.Errorf("expected memory usage to be at most %d, got %d", budget.MaxMemoryUsage(), budget.MemoryUsage())
+	}
+}
+
+func TestBudget_CallbacksAreCalledOutsideTheBudgetLock(t *testing.T) {
+	budget := NewBudget(2 * Kilobyte)
+	evicted := 0
+	first := NewCache().WithMaxSize(NoMaxSize).WithBudget(budget, 1)
+	second := NewCache().WithMaxSize(NoMaxSize).WithBudget(budget, 1)
+	first.WithOnEvicted(func(key string, value any, reason RemovalReason) {
+		// This would deadlock if the callback was called while the Budget's lock is held
+		budget.WithPolicy(ColdestTail)
+		evicted++
+	})
+	first.Set("1", make([]byte, 900))
+	second.Set("1", make([]byte, 900))
+	second.Set("2", make([]byte, 900))
+	if evicted != 1 {
+		t.Errorf("expected 1 entry to have been evicted from the first cache, got %d", evicted)
+	}
+	if budget.MemoryUsage() > budget.MaxMemoryUsage() {
+		t.Errorf("expected memory usage to be at most %d, got %d", budget.MaxMemoryUsage(), budget.MemoryUsage())
+	}
+}
+
+func TestBudget_EntryLargerThanBudgetIsRejected(t *testing.T) {
+	budget := NewBudget(2 * Kilobyte)
+	first := NewCache().WithMaxSize(NoMaxSize).WithBudget(budget, 1)
+	second := NewCache().WithMaxSize(NoMaxSize).WithMaxEntrySizeRatio(0.5).WithBudget(budget, 1)
+	first.Set("1", make([]byte, 500))
+	if first.MaxEntrySize() != budget.MaxMemoryUsage() || second.MaxEntrySize() != budget.MaxMemoryUsage()/2 {
+		t.Errorf("expected max entry sizes of %d and %d, got %d and %d", budget.MaxMemoryUsage(), budget.MaxMemoryUsage()/2, first.MaxEntrySize(), second.MaxEntrySize())
+	}
+	if err := second.TrySet("1", make([]byte, 3*Kilobyte)); err != ErrValueTooLarge {
+		t.Errorf("expected %v, got %v", ErrValueTooLarge, err)
+	}
+	if first.Count() != 1 {
+		t.Error("expected the entries of the other members to have been left untouched")
+	}
+}
@@ -0,0 +1,110 @@
+package gocache
+
+// RemovalReason is the reason why an entry was removed from the cache
+type RemovalReason string
+
+const (
+	// Evicted is the RemovalReason used when an entry is removed to make room for other entries, which happens when
+	// the cache's MaxSize or MaxMemoryUsage is exceeded
+	Evicted RemovalReason = "Evicted"
+
+	// Expired is the RemovalReason used when an expired entry is removed, whether it be through active deletion
+	// (i.e. Get) or through passive deletion (i.e. the janitor)
+	Expired RemovalReason = "Expired"
+
+	// Deleted is the RemovalReason used when an entry is explicitly deleted (i.e. Delete, DeleteAll,
+	// DeleteKeysByPattern or SetWithTTL with a TTL of 0)
+	Deleted RemovalReason = "Deleted"
+
+	// Replaced is the RemovalReason used when the value of an existing entry is overwritten by a new value.
+	// The value passed to the callback is the old value.
+	Replaced RemovalReason = "Replaced"
+
+	// Cleared is the RemovalReason used when an entry is removed as a result of Clear being called
+	Cleared RemovalReason = "Cleared"
+)
+
+// removal is a removal that happened while the cache's lock was held and that has yet to be passed to the
+// onEvicted callback
+type removal struct {
+	key    string
+	value  any
+	reason RemovalReason
+}
+
+// queueRemoval keeps track of a removal so that it can be passed to the onEvicted callback and published to the
+// subscribers once the lock is released.
+//
+// Must be called while the cache's lock is held.
+// When the reason is Replaced, this must be called before the entry's value is overwritten.
+func (cache *Cache) queueRemoval(entry *Entry, reason RemovalReason) {
+	// Replacing an entry's value is published as an EventSet for the new value instead
+	if reason != Replaced {
+		cache.queueEvent(reason.eventType(), entry)
+	}
+	if cache.onEvicted == nil {
+		return
+	}
+	cache.pendingRemovals = append(cache.pendingRemovals, removal{key: entry.Key, value: entry.Value, reason: reason})
+}
+
+// unlock releases the cache's lock and then passes every removal queued while the lock was held to the
+// onEvicted callback, followed by publishing every queued event to the subscribers. If the cache is part of a Budget
+// that is exceeded, entries are then evicted from the members of the Budget.
+//
+// Calling the callback outside the lock is what allows the callback to safely call the cache's functions.
+func (cache *Cache) unlock() {
+	budget := cache.budget
+	cache.release()
+	if budget != nil {
+		budget.enforce()
+	}
+}
+
+// release releases the cache's lock and then passes every removal queued while the lock was held to the onEvicted
+// callback, followed by publishing every queued event to the subscribers, but unlike unlock, it does not enforce the
+// cache's Budget
+func (cache *Cache) release() {
+	n := cache.takeNotifications()
+	cache.mutex.Unlock()
+	n.deliver()
+}
+
+// notifications are the removals and events queued while the cache's lock was held, which have yet to be passed to
+// the onEvicted callback and published to the subscribers
+type notifications struct {
+	cache         *Cache
+	onEvicted     func(key string, value any, reason RemovalReason)
+	removals      []removal
+	subscriptions []*subscription
+	events        []Event
+}
+
+// takeNotifications returns the removals and events queued so far, and clears them from the cache
+//
+// Must be called while the cache's lock is held.
+func (cache *Cache) takeNotifications() notifications {
+	n := notifications{
+		cache:         cache,
+		onEvicted:     cache.onEvicted,
+		removals:      cache.pendingRemovals,
+		subscriptions: cache.subscriptions,
+		events:        cache.pendingEvents,
+	}
+	cache.pendingRemovals, cache.pendingEvents = nil, nil
+	return n
+}
+
+// deliver passes the removals to the onEvicted callback, and then publishes the events to the subscribers
+//
+// Must be called while the cache's lock is NOT held.
+func (n notifications) deliver() {
+	for _, r := range n.removals {
+		n.onEvicted(r.key, r.value, r.reason)
+	}
+	for _, event := range n.events {
+		for _, s := range n.subscriptions {
+			s.publish(n.cache, event)
+		}
+	}
+}
@@ -0,0 +1,189 @@
+package gocache
+
+import (
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+type removalRecorder struct {
+	sync.Mutex
+	removals []removal
+}
+
+func (recorder *removalRecorder) record(key string, value any, reason RemovalReason) {
+	recorder.Lock()
+	recorder.removals = append(recorder.removals, removal{key: key, value: value, reason: reason})
+	recorder.Unlock()
+}
+
+func (recorder *removalRecorder) get() []removal {
+	recorder.Lock()
+	defer recorder.Unlock()
+	return append([]removal(nil), recorder.removals...)
+}
+
+func (recorder *removalRecorder) expect(t *testing.T, expected ...removal) {
+	t.Helper()
+	removals := recorder.get()
+	if len(removals) != len(expected) {
+		t.Fatalf("expected %d removals, got %d: %v", len(expected), len(removals), removals)
+	}
+	for i := range expected {
+		if removals[i] != expected[i] {
+			t.Errorf("expected removal #%d to be %v, got %v", i, expected[i], removals[i])
+		}
+	}
+}
+
+func TestCache_WithOnEvicted(t *testing.T) {
+	scenarios := []struct {
+		name     string
+		cache    *Cache
+		action   func(cache *Cache)
+		expected []removal
+	}{
+		{
+			name:  "evicted-by-max-size",
+			cache: NewCache().WithMaxSize(2),
+			action: func(cache *Cache) {
+				cache.Set("1", "v1")
+				cache.Set("2", "v2")
+				cache.Set("3", "v3")
+			},
+			expected: []removal{{key: "1", value: "v1", reason: Evicted}},
+		},
+		{
+			name:  "evicted-by-max-memory-usage",
+			cache: NewCache().WithMaxSize(NoMaxSize).WithMaxMemoryUsage(Kilobyte),
+			action: func(cache *Cache) {
+				cache.Set("1", strings.Repeat("a", 400))
+				cache.Set("2", strings.Repeat("b", 400))
+				cache.Set("3", strings.Repeat("c", 400))
+			},
+			expected: []removal{{key: "1", value: strings.Repeat("a", 400), reason: Evicted}},
+		},
+		{
+			name:  "expired-on-get",
+			cache: NewCache(),
+			action: func(cache *Cache) {
+				cache.SetWithTTL("1", "v1", time.Nanosecond)
+				time.Sleep(time.Millisecond)
+				cache.Get("1")
+			},
+			expected: []removal{{key: "1", value: "v1", reason: Expired}},
+		},
+		{
+			name:  "expired-on-get-all",
+			cache: NewCache(),
+			action: func(cache *Cache) {
+				cache.Set("1", "v1")
+				cache.SetWithTTL("2", "v2", time.Nanosecond)
+				time.Sleep(time.Millisecond)
+				cache.GetAll()
+			},
+			expected: []removal{{key: "2", value: "v2", reason: Expired}},
+		},
+		{
+			name:  "deleted",
+			cache: NewCache(),
+			action: func(cache *Cache) {
+				cache.Set("1", "v1")
+				cache.Delete("1")
+				cache.Delete("key-that-does-not-exist")
+			},
+			expected: []removal{{key: "1", value: "v1", reason: Deleted}},
+		},
+		{
+			name:  "deleted-by-delete-all",
+			cache: NewCache(),
+			action: func(cache *Cache) {
+				cache.Set("1", "v1")
+				cache.Set("2", "v2")
+				cache.DeleteAll([]string{"1", "2"})
+			},
+			expected: []removal{{key: "1", value: "v1", reason: Deleted}, {key: "2", value: "v2", reason: Deleted}},
+		},
+		{
+			name:  "deleted-by-delete-keys-by-pattern",
+			cache: NewCache(),
+			action: func(cache *Cache) {
+				cache.Set("a1", "v1")
+				cache.Set("b1", "v2")
+				cache.DeleteKeysByPattern("a*")
+			},
+			expected: []removal{{key: "a1", value: "v1", reason: Deleted}},
+		},
+		{
+			name:  "deleted-by-set-with-ttl-of-zero",
+			cache: NewCache(),
+			action: func(cache *Cache) {
+				cache.Set("1", "v1")
+				cache.SetWithTTL("1", "v2", 0)
+			},
+			expected: []removal{{key: "1", value: "v1", reason: Deleted}},
+		},
+		{
+			name:  "replaced",
+			cache: NewCache(),
+			action: func(cache *Cache) {
+				cache.Set("1", "v1")
+				cache.Set("1", "v2")
+			},
+			expected: []removal{{key: "1", value: "v1", reason: Replaced}},
+		},
+		{
+			name:  "cleared",
+			cache: NewCache(),
+			action: func(cache *Cache) {
+				cache.Set("1", "v1")
+				cache.Clear()
+			},
+			expected: []removal{{key: "1", value: "v1", reason: Cleared}},
+		},
+	}
+	for _, scenario := range scenarios {
+		t.Run(scenario.name, func(t *testing.T) {
+			recorder := &removalRecorder{}
+			scenario.action(scenario.cache.WithOnEvicted(recorder.record))
+			recorder.expect(t, scenario.expected...)
+		})
+	}
+}
+
+func TestCache_WithOnEvictedWhenExpiredByJanitor(t *testing.T) {
+	recorder := &removalRecorder{}
+	cache := NewCache().WithOnEvicted(recorder.record)
+	cache.SetWithTTL("1", "v1", time.Nanosecond)
+	if err := cache.StartJanitor(); err != nil {
+		t.Fatal(err)
+	}
+	defer cache.StopJanitor()
+	for start := time.Now(); time.Since(start) < JanitorMaxShiftBackOff && len(recorder.get()) == 0; {
+		time.Sleep(JanitorMinShiftBackOff)
+	}
+	recorder.expect(t, removal{key: "1", value: "v1", reason: Expired})
+}
+
+func TestCache_WithOnEvictedCanCallCacheFunctions(t *testing.T) {
+	var cache *Cache
+	cache = NewCache().WithMaxSize(1).WithOnEvicted(func(key string, value any, reason RemovalReason) {
+		if reason == Evicted {
+			// If the callback were called while the lock is held, this would deadlock
+			cache.Get(key)
+			cache.Set("evicted", key)
+		}
+	})
+	done := make(chan struct{})
+	go func() {
+		cache.Set("1", "v1")
+		cache.Set("2", "v2")
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("calling cache functions from the callback should not deadlock")
+	}
+}
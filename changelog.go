@@ -0,0 +1,153 @@
+package gocache
+
+import "errors"
+
+var (
+	ErrChangeLogDisabled = errors.New("change log is disabled")                                    // Returned when the change log has not been enabled
+	ErrCursorOutOfRange  = errors.New("cursor is no longer in the change log, a resync is needed") // Returned when the changes following a cursor are no longer retained
+)
+
+// Change is a mutation recorded in the change log
+type Change struct {
+	// Sequence is the sequence number of the change. Sequence numbers start at 1 and are incremented by 1 for every
+	// change, which means that there are no gaps between the sequence numbers of two consecutive changes.
+	Sequence uint64
+
+	// Type is the type of the change (EventSet, EventDelete, EventExpire, EventEvict or EventClear)
+	Type EventMask
+
+	// Key is the key of the entry affected by the change
+	Key string
+
+	// Value is the value of the entry. For EventSet, this is the new value, and for every other event type, this
+	// is the value that the entry had when it was removed.
+	Value any
+
+	// Expiration is the unix time in nanoseconds at which the entry expires (-1 means no expiration)
+	Expiration int64
+}
+
+// changeLog is a ring buffer containing the most recent changes made to the cache
+type changeLog struct {
+	// changes is the ring buffer; its length is the maximum number of changes retained
+	changes []Change
+
+	// start is the index of the oldest change in changes
+	start int
+
+	// count is the number of changes currently retained
+	count int
+
+	// lastSequence is the sequence number of the most recent change
+	lastSequence uint64
+}
+
+func newChangeLog(capacity int) *changeLog {
+	return &changeLog{changes: make([]Change, capacity)}
+}
+
+// append records a change, overwriting the oldest change if the change log is full
+func (log *changeLog) append(eventType EventMask, entry *Entry) {
+	log.lastSequence++
+	change := Change{
+		Sequence:   log.lastSequence,
+		Type:       eventType,
+		Key:        entry.Key,
+		Value:      entry.Value,
+		Expiration: entry.Expiration,
+	}
+	if log.count < len(log.changes) {
+		log.changes[(log.start+log.count)%len(log.changes)] = change
+		log.count++
+	} else {
+		log.changes[log.start] = change
+		log.start = (log.start + 1) % len(log.changes)
+	}
+}
+
+// since returns up to limit changes that have a sequence number greater than sequence
+func (log *changeLog) since(sequence uint64, limit int) ([]Change, error) {
+	if sequence == log.lastSequence {
+		return nil, nil
+	}
+	if sequence > log.lastSequence {
+		// This can only happen if the change log was reset, in which case the consumer must resync as well
+		return nil, ErrCursorOutOfRange
+	}
+	oldestSequence := log.lastSequence - uint64(log.count) + 1
+	if sequence+1 < oldestSequence {
+		return nil, ErrCursorOutOfRange
+	}
+	offset := int(sequence + 1 - oldestSequence)
+	numberOfChanges := log.count - offset
+	if limit > 0 && limit < numberOfChanges {
+		numberOfChanges = limit
+	}
+	changes := make([]Change, numberOfChanges)
+	for i := range changes {
+		changes[i] = log.changes[(log.start+offset+i)%len(log.changes)]
+	}
+	return changes, nil
+}
+
+// WithChangeLog enables the change log, which assigns a sequence number to every mutation made to the cache (set,
+// delete, expire, evict and clear) and retains the most recent mutations so that they can be retrieved using
+// Cache.ChangesSince.
+//
+// The capacity is the maximum number of changes retained. A capacity of 0 or less disables the change log.
+//
+// Note that enabling the change log resets it, meaning that sequence numbers start from 1 again.
+func (cache *Cache) WithChangeLog(capacity int) *Cache {
+	cache.mutex.Lock()
+	if capacity > 0 {
+		cache.changeLog = newChangeLog(capacity)
+	} else {
+		cache.changeLog = nil
+	}
+	cache.mutex.Unlock()
+	return cache
+}
+
+// ChangesSince returns the changes with a sequence number greater than the sequence passed as parameter, ordered
+// by sequence number. A sequence of 0 is not special: it returns every change made since the change log was enabled,
+// which is only possible as long as none of them have been dropped from the change log, so consumers that didn't
+// receive any change yet should start from the sequence returned by Cache.Snapshot instead.
+// If the limit is set to 0, all changes following the sequence are returned.
+// If the limit is above 0, no more than the specified number of changes are returned.
+//
+// To consume the change log, pass the Sequence of the last change received to the next call to ChangesSince.
+//
+// If the changes following the sequence are no longer retained because the change log is full,
+// ErrCursorOutOfRange is returned. When that happens, the consumer missed some changes and must resync
+// using Cache.Snapshot before resuming from the sequence returned by the latter.
+//
+// Returns ErrChangeLogDisabled if the change log was not enabled through Cache.WithChangeLog.
+func (cache *Cache) ChangesSince(sequence uint64, limit int) ([]Change, error) {
+	cache.mutex.RLock()
+	defer cache.mutex.RUnlock()
+	if cache.changeLog == nil {
+		return nil, ErrChangeLogDisabled
+	}
+	return cache.changeLog.since(sequence, limit)
+}
+
+// Snapshot returns all entries that have not expired as well as the sequence number of the last change reflected
+// in these entries, which can then be passed to Cache.ChangesSince to follow the changes made after the snapshot.
+//
+// Unlike GetAll, this does not delete expired entries nor does it affect the statistics.
+//
+// Returns ErrChangeLogDisabled if the change log was not enabled through Cache.WithChangeLog.
+func (cache *Cache) Snapshot() (map[string]any, uint64, error) {
+	cache.mutex.RLock()
+	defer cache.mutex.RUnlock()
+	if cache.changeLog == nil {
+		return nil, 0, ErrChangeLogDisabled
+	}
+	entries := make(map[string]any, len(cache.entries))
+	for key, entry := range cache.entries {
+		if !entry.Expired() {
+			entries[key] = entry.Value
+		}
+	}
+	return entries, cache.changeLog.lastSequence, nil
+}
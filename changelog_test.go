@@ -0,0 +1,180 @@
+package gocache
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCache_ChangesSince(t *testing.T) {
+	cache := NewCache().WithMaxSize(2).WithChangeLog(100)
+	cache.Set("1", "v1")
+	cache.SetWithTTL("1", "v2", time.Hour)
+	cache.Set("2", "v3")
+	cache.Set("3", "v4")
+	cache.Delete("2")
+	cache.SetWithTTL("4", "v5", time.Nanosecond)
+	time.Sleep(time.Millisecond)
+	cache.Get("4")
+	cache.Clear()
+	expectedChanges := []Change{
+		{Sequence: 1, Type: EventSet, Key: "1", Value: "v1"},
+		{Sequence: 2, Type: EventSet, Key: "1", Value: "v2"},
+		{Sequence: 3, Type: EventSet, Key: "2", Value: "v3"},
+		{Sequence: 4, Type: EventSet, Key: "3", Value: "v4"},
+		{Sequence: 5, Type: EventEvict, Key: "1", Value: "v2"},
+		{Sequence: 6, Type: EventDelete, Key: "2", Value: "v3"},
+		{Sequence: 7, Type: EventSet, Key: "4", Value: "v5"},
+		{Sequence: 8, Type: EventExpire, Key: "4", Value: "v5"},
+		{Sequence: 9, Type: EventClear, Key: "3", Value: "v4"},
+	}
+	changes, err := cache.ChangesSince(0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(changes) != len(expectedChanges) {
+		t.Fatalf("expected %d changes, got %d", len(expectedChanges), len(changes))
+	}
+	for i, expectedChange := range expectedChanges {
+		change := changes[i]
+		if change.Sequence != expectedChange.Sequence || change.Type != expectedChange.Type || change.Key != expectedChange.Key || change.Value != expectedChange.Value {
+			t.Errorf("expected change #%d to be %v, got %v", i, expectedChange, change)
+		}
+	}
+	if changes[0].Expiration != NoExpiration {
+		t.Error("expected the first change to have no expiration")
+	}
+	if changes[1].Expiration <= time.Now().UnixNano() {
+		t.Error("expected the second change to have an expiration in the future")
+	}
+	// Resume from a cursor, with a limit
+	changes, err = cache.ChangesSince(4, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(changes) != 2 || changes[0].Sequence != 5 || changes[1].Sequence != 6 {
+		t.Errorf("expected changes 5 and 6, got %v", changes)
+	}
+	// Cursor is already at the latest change
+	changes, err = cache.ChangesSince(9, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(changes) != 0 {
+		t.Errorf("expected no changes, got %v", changes)
+	}
+}
+
+func TestCache_ChangesSinceWhenCursorIsOutOfRange(t *testing.T) {
+	cache := NewCache().WithChangeLog(3)
+	for _, key := range []string{"1", "2", "3", "4", "5"} {
+		cache.Set(key, key)
+	}
+	// Only changes 3, 4 and 5 are retained, so a consumer whose last change is 2 can still resume
+	changes, err := cache.ChangesSince(2, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(changes) != 3 || changes[0].Key != "3" || changes[2].Key != "5" {
+		t.Errorf("expected changes for keys 3 to 5, got %v", changes)
+	}
+	// But a consumer whose last change is 1 missed change 2
+	if _, err = cache.ChangesSince(1, 0); err != ErrCursorOutOfRange {
+		t.Errorf("expected %v, got %v", ErrCursorOutOfRange, err)
+	}
+	if _, err = cache.ChangesSince(0, 0); err != ErrCursorOutOfRange {
+		t.Errorf("expected %v, got %v", ErrCursorOutOfRange, err)
+	}
+	if _, err = cache.ChangesSince(6, 0); err != ErrCursorOutOfRange {
+		t.Errorf("expected %v, got %v", ErrCursorOutOfRange, err)
+	}
+}
+
+func TestCache_ChangesSinceWithCursorZeroAfterChangeLogWrapped(t *testing.T) {
+	cache := NewCache().WithChangeLog(3)
+	for _, key := range []string{"1", "2"} {
+		cache.Set(key, key)
+	}
+	// As long as no change was dropped, 0 returns every change
+	if changes, err := cache.ChangesSince(0, 0); err != nil || len(changes) != 2 {
+		t.Errorf("expected 2 changes, got %v (err=%v)", changes, err)
+	}
+	for _, key := range []string{"3", "4", "5"} {
+		cache.Set(key, key)
+	}
+	// Changes 1 and 2 were dropped, so a consumer starting from 0 must resync using Snapshot
+	if _, err := cache.ChangesSince(0, 0); err != ErrCursorOutOfRange {
+		t.Errorf("expected %v, got %v", ErrCursorOutOfRange, err)
+	}
+	entries, sequence, err := cache.Snapshot()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 5 || sequence != 5 {
+		t.Errorf("expected a snapshot of 5 entries at sequence 5, got %d entries at sequence %d", len(entries), sequence)
+	}
+	cache.Set("6", "6")
+	if changes, err := cache.ChangesSince(sequence, 0); err != nil || len(changes) != 1 || changes[0].Key != "6" {
+		t.Errorf("expected the change for key 6, got %v (err=%v)", changes, err)
+	}
+}
+
+func TestCache_ChangesSinceAfterExpire(t *testing.T) {
+	cache := NewCache().WithChangeLog(10)
+	events, cancel := cache.Subscribe("*", EventSet)
+	defer cancel()
+	cache.Set("1", "v1")
+	if !cache.Expire("1", time.Hour) {
+		t.Fatal("expected Expire to return true")
+	}
+	changes, err := cache.ChangesSince(1, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(changes) != 1 || changes[0].Type != EventSet || changes[0].Key != "1" || changes[0].Value != "v1" {
+		t.Fatalf("expected an EventSet change for key 1, got %v", changes)
+	}
+	if changes[0].Expiration <= time.Now().UnixNano() {
+		t.Error("expected the change to have an expiration in the future")
+	}
+	receiveEvent(t, events)
+	if event := receiveEvent(t, events); event != (Event{Type: EventSet, Key: "1", Value: "v1"}) {
+		t.Errorf("expected an EventSet event for key 1, got %v", event)
+	}
+}
+
+func TestCache_ChangesSinceWhenChangeLogIsDisabled(t *testing.T) {
+	cache := NewCache()
+	cache.Set("1", "v1")
+	if _, err := cache.ChangesSince(0, 0); err != ErrChangeLogDisabled {
+		t.Errorf("expected %v, got %v", ErrChangeLogDisabled, err)
+	}
+	if _, _, err := cache.Snapshot(); err != ErrChangeLogDisabled {
+		t.Errorf("expected %v, got %v", ErrChangeLogDisabled, err)
+	}
+}
+
+func TestCache_Snapshot(t *testing.T) {
+	cache := NewCache().WithChangeLog(2)
+	cache.Set("1", "v1")
+	cache.Set("2", "v2")
+	cache.SetWithTTL("3", "v3", time.Nanosecond)
+	time.Sleep(time.Millisecond)
+	entries, sequence, err := cache.Snapshot()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 2 || entries["1"] != "v1" || entries["2"] != "v2" {
+		t.Errorf("expected snapshot to contain keys 1 and 2, got %v", entries)
+	}
+	if sequence != 3 {
+		t.Errorf("expected sequence to be 3, got %d", sequence)
+	}
+	cache.Set("4", "v4")
+	changes, err := cache.ChangesSince(sequence, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(changes) != 1 || changes[0].Key != "4" {
+		t.Errorf("expected a single change for key 4, got %v", changes)
+	}
+}
@@ -0,0 +1,81 @@
+package gocache
+
+import "time"
+
+// NoMaxCost means that the cache has no maximum total cost
+const NoMaxCost = 0
+
+// useCostFunc is passed as cost by functions that don't take a cost, and is resolved by Cache.measure to the cost
+// computed by the cost function
+const useCostFunc = -1
+
+// DefaultCost is the cost of the entries written without specifying a cost when the cache has no cost function
+const DefaultCost = 1
+
+// WithMaxCost sets the maximum total cost of the entries in the cache at any given time, which makes it possible to
+// bound the cache based on what the entries are worth (e.g. how expensive they are to recompute) rather than on how
+// many there are or how much memory they take. Entries are evicted from the tail until the total cost no longer
+// exceeds maxCost, the same way they are for MaxSize and MaxMemoryUsage.
+//
+// The cost of an entry is either passed to SetWithCost, or computed by the cost function (see WithCostFunc).
+//
+// Setting this to NoMaxCost will disable eviction by cost
+func (cache *Cache) WithMaxCost(maxCost int) *Cache {
+	if maxCost < 0 {
+		maxCost = NoMaxCost
+	}
+	cache.mutex.Lock()
+	cache.maxCost = maxCost
+	cache.evictIfNecessary()
+	cache.unlock()
+	return cache
+}
+
+// WithCostFunc sets the function used to compute the cost of the entries written without specifying a cost (e.g. Set).
+// Negative costs are treated as 0.
+//
+// Defaults to nil, meaning that every entry has a cost of DefaultCost, in which case MaxCost behaves like MaxSize.
+//
+// Note that the cost of the existing entries is not recomputed.
+func (cache *Cache) WithCostFunc(costFunc func(key string, value any) int) *Cache {
+	cache.mutex.Lock()
+	cache.costFunc = costFunc
+	cache.mutex.Unlock()
+	return cache
+}
+
+// MaxCost returns the configured maxCost of the cache
+func (cache *Cache) MaxCost() int {
+	return cache.maxCost
+}
+
+// SetWithCost creates or updates a key with a given value, TTL and cost, which takes precedence over the cost
+// computed by the cost function (see WithCostFunc). Negative costs are treated as 0. Entries whose cost alone exceeds
+// the cache's MaxCost are rejected, as the cache would otherwise evict every other entry before evicting the new entry
+// as well.
+//
+// Like with SetWithTTL, if a negative TTL that isn't -1 (NoExpiration) is provided, the entry will not be created if
+// the key doesn't exist.
+//
+// If the write is rejected, the key is deleted, like with SetWithTTL.
+//
+// Note that the cost only applies to this write: if the entry is updated later on without specifying a cost, its cost
+// is computed by the cost function again.
+func (cache *Cache) SetWithCost(key string, value any, ttl time.Duration, cost int) {
+	value = cache.normalizeValue(value)
+	cache.mutex.Lock()
+	defer cache.unlock()
+	if _, err := cache.trySet(key, value, ttl, max(cost, 0)); err != nil {
+		cache.delete(key)
+	} else {
+		cache.evictIfNecessary()
+	}
+}
+
+// setCost sets the cost of an entry, and updates the total cost of the cache accordingly
+//
+// Must be called while the cache's lock is held.
+func (cache *Cache) setCost(entry *Entry, cost int) {
+	cache.totalCost += cost - entry.cost
+	entry.cost = cost
+}
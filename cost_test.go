@@ -0,0 +1,93 @@
+package gocache
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCache_WithMaxCost(t *testing.T) {
+	cache := NewCache().WithMaxSize(NoMaxSize).WithMaxCost(10)
+	cache.SetWithCost("cheap-1", "value", NoExpiration, 1)
+	cache.SetWithCost("expensive", "value", NoExpiration, 8)
+	cache.SetWithCost("cheap-2", "value", NoExpiration, 1)
+	if stats := cache.Stats(); stats.TotalCost != 10 {
+		t.Errorf("expected total cost to be 10, got %d", stats.TotalCost)
+	}
+	cache.SetWithCost("medium", "value", NoExpiration, 5)
+	// cheap-1 and expensive must be evicted for the total cost to go back under 10
+	if cache.Count() != 2 {
+		t.Errorf("expected 2 entries to be left, got %d", cache.Count())
+	}
+	if _, exists := cache.Get("expensive"); exists {
+		t.Error("expected expensive to have been evicted")
+	}
+	stats := cache.Stats()
+	if stats.TotalCost != 6 {
+		t.Errorf("expected total cost to be 6, got %d", stats.TotalCost)
+	}
+	if stats.EvictedKeys != 2 || stats.EvictedCost != 9 {
+		t.Errorf("expected 2 keys with a total cost of 9 to have been evicted, got %d keys with a total cost of %d", stats.EvictedKeys, stats.EvictedCost)
+	}
+	cache.Delete("medium")
+	if stats := cache.Stats(); stats.TotalCost != 1 {
+		t.Errorf("expected total cost to be 1 after deleting an entry, got %d", stats.TotalCost)
+	}
+	cache.Clear()
+	if stats := cache.Stats(); stats.TotalCost != 0 {
+		t.Errorf("expected total cost to be 0 after clearing the cache, got %d", stats.TotalCost)
+	}
+}
+
+func TestCache_WithMaxCostAndDefaultCost(t *testing.T) {
+	cache := NewCache().WithMaxSize(NoMaxSize).WithMaxCost(3)
+	for _, key := range []string{"1", "2", "3", "4"} {
+		cache.Set(key, "value")
+	}
+	if cache.Count() != 3 {
+		t.Errorf("expected every entry to have a cost of %d by default, got %d entries", DefaultCost, cache.Count())
+	}
+	if info, _ := cache.Inspect("4"); info.Cost != DefaultCost {
+		t.Errorf("expected cost to be %d, got %d", DefaultCost, info.Cost)
+	}
+}
+
+func TestCache_WithCostFunc(t *testing.T) {
+	cache := NewCache().WithMaxCost(100).WithCostFunc(func(key string, value any) int {
+		return len(value.(string))
+	})
+	cache.Set("a", "0123456789")
+	if info, _ := cache.Inspect("a"); info.Cost != 10 {
+		t.Errorf("expected cost to be 10, got %d", info.Cost)
+	}
+	cache.SetWithCost("a", "0123456789", time.Hour, 50)
+	if info, _ := cache.Inspect("a"); info.Cost != 50 {
+		t.Errorf("expected cost passed to SetWithCost to take precedence, got %d", info.Cost)
+	}
+	// Updating the entry without specifying a cost computes its cost again
+	cache.Set("a", "01234")
+	if stats := cache.Stats(); stats.TotalCost != 5 {
+		t.Errorf("expected total cost to be 5, got %d", stats.TotalCost)
+	}
+	cache.SetWithCost("b", "value", time.Hour, -5)
+	if info, _ := cache.Inspect("b"); info.Cost != 0 {
+		t.Errorf("expected negative costs to be treated as 0, got %d", info.Cost)
+	}
+	cache.SetWithCost("b", "value", -time.Hour, 20)
+	if _, exists := cache.Get("b"); exists {
+		t.Error("expected entry to have been deleted because of the negative TTL")
+	}
+	if stats := cache.Stats(); stats.TotalCost != 5 {
+		t.Errorf("expected total cost to be 5, got %d", stats.TotalCost)
+	}
+}
+
+func TestCache_WithMaxCostAfterEntriesWereAdded(t *testing.T) {
+	cache := NewCache()
+	for _, key := range []string{"1", "2", "3", "4"} {
+		cache.SetWithCost(key, "value", NoExpiration, 5)
+	}
+	cache.WithMaxCost(10)
+	if cache.Count() != 2 || cache.MaxCost() != 10 {
+		t.Errorf("expected entries to have been evicted when the max cost was set, got %d entries", cache.Count())
+	}
+}
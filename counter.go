@@ -0,0 +1,163 @@
+package gocache
+
+import (
+	"errors"
+	"math"
+	"reflect"
+	"time"
+)
+
+var (
+	ErrNotNumeric      = errors.New("value is not numeric")                                   // Returned when attempting to increment or decrement a value that is not numeric
+	ErrNotInteger      = errors.New("value is not an integer")                                // Returned when attempting to increment or decrement a float using Increment or Decrement
+	ErrNumericOverflow = errors.New("increment or decrement would overflow the value's type") // Returned when incrementing or decrementing a value would overflow its type
+)
+
+// Increment increments the value of a key by delta and returns the new value.
+//
+// If the key does not exist (or has expired), it is created with delta as value (as an int64) and the default TTL.
+// If the key exists, its expiration time is preserved, and so is the type of its value, meaning that incrementing
+// an int32 results in an int32.
+//
+// Returns ErrNotInteger if the value is a float (use IncrementFloat instead), ErrNotNumeric if the value is not an
+// integer (int, int8, int16, int32, int64, uint, uint8, uint16, uint32 or uint64) either, ErrNumericOverflow if the new value does not fit in the value's type, and the error returned by
+// TrySetWithTTL if the write was rejected. In every case, the value is left unchanged.
+func (cache *Cache) Increment(key string, delta int64) (int64, error) {
+	return cache.increment(key, delta, useDefaultTTL)
+}
+
+// increment increments the value of a key by delta, creating the key with the TTL passed as parameter if it
+// does not exist
+func (cache *Cache) increment(key string, delta int64, ttl time.Duration) (int64, error) {
+	cache.mutex.Lock()
+	defer cache.unlock()
+	entry, ok := cache.getUnexpired(key)
+	if !ok {
+		if _, err := cache.trySet(key, delta, ttl, useCostFunc); err != nil {
+			return 0, err
+		}
+		cache.evictIfNecessary()
+		return delta, nil
+	}
+	newValue, result, err := incrementInteger(entry.Value, delta)
+	if err != nil {
+		return 0, err
+	}
+	size, cost := cache.measure(key, newValue, useCostFunc)
+	if err := cache.checkAdmission(key, newValue, size, cost); err != nil {
+		return 0, err
+	}
+	cache.setWithExpiration(key, newValue, entry.Expiration, size, cost)
+	cache.evictIfNecessary()
+	return result, nil
+}
+
+// Decrement decrements the value of a key by delta and returns the new value.
+//
+// See Increment for details.
+func (cache *Cache) Decrement(key string, delta int64) (int64, error) {
+	return cache.decrement(key, delta, useDefaultTTL)
+}
+
+func (cache *Cache) decrement(key string, delta int64, ttl time.Duration) (int64, error) {
+	if delta == math.MinInt64 {
+		return 0, ErrNumericOverflow
+	}
+	return cache.increment(key, -delta, ttl)
+}
+
+// IncrementFloat increments the value of a key by delta and returns the new value.
+//
+// If the key does not exist (or has expired), it is created with delta as value (as a float64) and the default TTL.
+// If the key exists, its expiration time is preserved. If the value is a float32, the new value is also a float32,
+// otherwise, the new value is a float64, which means that incrementing an integer converts it to a float64.
+//
+// Returns ErrNotNumeric if the value is neither a float nor an integer, and the error returned by TrySetWithTTL if the
+// write was rejected. In both cases, the value is left unchanged.
+func (cache *Cache) IncrementFloat(key string, delta float64) (float64, error) {
+	return cache.incrementFloat(key, delta, useDefaultTTL)
+}
+
+func (cache *Cache) incrementFloat(key string, delta float64, ttl time.Duration) (float64, error) {
+	cache.mutex.Lock()
+	defer cache.unlock()
+	entry, ok := cache.getUnexpired(key)
+	if !ok {
+		if _, err := cache.trySet(key, delta, ttl, useCostFunc); err != nil {
+			return 0, err
+		}
+		cache.evictIfNecessary()
+		return delta, nil
+	}
+	var newValue any
+	var result float64
+	switch v := reflect.ValueOf(entry.Value); v.Kind() {
+	case reflect.Float32:
+		newValue = float32(v.Float() + delta)
+		result = float64(newValue.(float32))
+	case reflect.Float64:
+		result = v.Float() + delta
+		newValue = result
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		result = float64(v.Int()) + delta
+		newValue = result
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		result = float64(v.Uint()) + delta
+		newValue = result
+	default:
+		return 0, ErrNotNumeric
+	}
+	size, cost := cache.measure(key, newValue, useCostFunc)
+	if err := cache.checkAdmission(key, newValue, size, cost); err != nil {
+		return 0, err
+	}
+	cache.setWithExpiration(key, newValue, entry.Expiration, size, cost)
+	cache.evictIfNecessary()
+	return result, nil
+}
+
+// incrementInteger increments an integer by delta while preserving its type
+//
+// Returns the new value, the new value as an int64 and an error if the value is not an integer or if the new value
+// does not fit in the value's type.
+func incrementInteger(value any, delta int64) (any, int64, error) {
+	v := reflect.ValueOf(value)
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		current := v.Int()
+		result := current + delta
+		if (delta > 0 && result < current) || (delta < 0 && result > current) {
+			return nil, 0, ErrNumericOverflow
+		}
+		newValue := reflect.New(v.Type()).Elem()
+		if newValue.OverflowInt(result) {
+			return nil, 0, ErrNumericOverflow
+		}
+		newValue.SetInt(result)
+		return newValue.Interface(), result, nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		current := v.Uint()
+		var result uint64
+		if delta >= 0 {
+			result = current + uint64(delta)
+			if result < current {
+				return nil, 0, ErrNumericOverflow
+			}
+		} else {
+			if uint64(-delta) > current {
+				return nil, 0, ErrNumericOverflow
+			}
+			result = current - uint64(-delta)
+		}
+		newValue := reflect.New(v.Type()).Elem()
+		if newValue.OverflowUint(result) || result > math.MaxInt64 {
+			return nil, 0, ErrNumericOverflow
+		}
+		newValue.SetUint(result)
+		return newValue.Interface(), int64(result), nil
+	case reflect.Float32, reflect.Float64:
+		return nil, 0, ErrNotInteger
+	default:
+		return nil, 0, ErrNotNumeric
+	}
+}
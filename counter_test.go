@@ -0,0 +1,208 @@
+package gocache
+
+import (
+	"math"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestCache_Increment(t *testing.T) {
+	cache := NewCache().WithDefaultTTL(time.Hour)
+	value, err := cache.Increment("counter", 5)
+	if err != nil || value != 5 {
+		t.Errorf("expected 5, got %d (err=%v)", value, err)
+	}
+	if stored := cache.GetValue("counter"); stored != int64(5) {
+		t.Errorf("expected the value to be stored as an int64, got %T", stored)
+	}
+	if ttl, err := cache.TTL("counter"); err != nil || ttl < 59*time.Minute {
+		t.Errorf("expected the default TTL to be used for a new key, got %s (err=%v)", ttl, err)
+	}
+	value, err = cache.Increment("counter", 2)
+	if err != nil || value != 7 {
+		t.Errorf("expected 7, got %d (err=%v)", value, err)
+	}
+	value, err = cache.Decrement("counter", 10)
+	if err != nil || value != -3 {
+		t.Errorf("expected -3, got %d (err=%v)", value, err)
+	}
+}
+
+func TestCache_IncrementPreservesExpiration(t *testing.T) {
+	cache := NewCache()
+	cache.SetWithTTL("counter", 1, time.Minute)
+	expiration := cache.entries["counter"].Expiration
+	if _, err := cache.Increment("counter", 1); err != nil {
+		t.Fatal(err)
+	}
+	if cache.entries["counter"].Expiration != expiration {
+		t.Error("expected the expiration to have been preserved")
+	}
+	cache.Set("counter-without-ttl", 1)
+	if _, err := cache.Increment("counter-without-ttl", 1); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := cache.TTL("counter-without-ttl"); err != ErrKeyHasNoExpiration {
+		t.Errorf("expected %v, got %v", ErrKeyHasNoExpiration, err)
+	}
+}
+
+func TestCache_IncrementWhenExpired(t *testing.T) {
+	cache := NewCache()
+	cache.SetWithTTL("counter", 100, time.Nanosecond)
+	time.Sleep(time.Millisecond)
+	if value, err := cache.Increment("counter", 1); err != nil || value != 1 {
+		t.Errorf("expected the expired counter to be recreated with a value of 1, got %d (err=%v)", value, err)
+	}
+}
+
+func TestCache_IncrementPreservesType(t *testing.T) {
+	type CustomInt int
+	scenarios := []struct {
+		value    any
+		delta    int64
+		expected any
+	}{
+		{value: 1, delta: 1, expected: 2},
+		{value: int8(1), delta: 1, expected: int8(2)},
+		{value: int16(1), delta: 1, expected: int16(2)},
+		{value: int32(1), delta: 1, expected: int32(2)},
+		{value: int64(1), delta: 1, expected: int64(2)},
+		{value: uint(1), delta: 1, expected: uint(2)},
+		{value: uint8(1), delta: 1, expected: uint8(2)},
+		{value: uint16(1), delta: 1, expected: uint16(2)},
+		{value: uint32(1), delta: 1, expected: uint32(2)},
+		{value: uint64(2), delta: -1, expected: uint64(1)},
+		{value: CustomInt(1), delta: 1, expected: CustomInt(2)},
+	}
+	cache := NewCache()
+	for _, scenario := range scenarios {
+		cache.Set("counter", scenario.value)
+		if _, err := cache.Increment("counter", scenario.delta); err != nil {
+			t.Errorf("unexpected error for %T: %v", scenario.value, err)
+		}
+		if value := cache.GetValue("counter"); value != scenario.expected {
+			t.Errorf("expected %v (%T), got %v (%T)", scenario.expected, scenario.expected, value, value)
+		}
+	}
+}
+
+func TestCache_IncrementOverflow(t *testing.T) {
+	scenarios := []struct {
+		value any
+		delta int64
+	}{
+		{value: int8(math.MaxInt8), delta: 1},
+		{value: int8(math.MinInt8), delta: -1},
+		{value: int64(math.MaxInt64), delta: 1},
+		{value: uint8(math.MaxUint8), delta: 1},
+		{value: uint(0), delta: -1},
+		{value: uint64(math.MaxUint64), delta: 0},
+	}
+	cache := NewCache()
+	for _, scenario := range scenarios {
+		cache.Set("counter", scenario.value)
+		if _, err := cache.Increment("counter", scenario.delta); err != ErrNumericOverflow {
+			t.Errorf("expected %v for %v (%T) + %d, got %v", ErrNumericOverflow, scenario.value, scenario.value, scenario.delta, err)
+		}
+		if value := cache.GetValue("counter"); value != scenario.value {
+			t.Errorf("expected value to be left unchanged, got %v", value)
+		}
+	}
+	cache.Set("counter", 0)
+	if _, err := cache.Decrement("counter", math.MinInt64); err != ErrNumericOverflow {
+		t.Errorf("expected %v, got %v", ErrNumericOverflow, err)
+	}
+}
+
+func TestCache_IncrementWhenValueIsNotNumeric(t *testing.T) {
+	cache := NewCache()
+	cache.Set("key", "value")
+	if _, err := cache.Increment("key", 1); err != ErrNotNumeric {
+		t.Errorf("expected %v, got %v", ErrNotNumeric, err)
+	}
+	cache.Set("key", 1.5)
+	if _, err := cache.Increment("key", 1); err != ErrNotInteger {
+		t.Errorf("expected %v, got %v", ErrNotInteger, err)
+	}
+	if _, err := cache.Decrement("key", 1); err != ErrNotInteger {
+		t.Errorf("expected %v, got %v", ErrNotInteger, err)
+	}
+	if value := cache.GetValue("key"); value != 1.5 {
+		t.Errorf("expected the value to have been left unchanged, got %v", value)
+	}
+	if _, err := cache.IncrementFloat("key", 1); err != nil {
+		t.Error("expected IncrementFloat to work on a float, got", err)
+	}
+	cache.Set("key", []int{1})
+	if _, err := cache.IncrementFloat("key", 1); err != ErrNotNumeric {
+		t.Errorf("expected %v, got %v", ErrNotNumeric, err)
+	}
+}
+
+func TestCache_IncrementFloat(t *testing.T) {
+	cache := NewCache()
+	if value, err := cache.IncrementFloat("counter", 1.5); err != nil || value != 1.5 {
+		t.Errorf("expected 1.5, got %f (err=%v)", value, err)
+	}
+	if value, err := cache.IncrementFloat("counter", 1.25); err != nil || value != 2.75 {
+		t.Errorf("expected 2.75, got %f (err=%v)", value, err)
+	}
+	cache.Set("float32", float32(1))
+	if value, err := cache.IncrementFloat("float32", 0.5); err != nil || value != 1.5 {
+		t.Errorf("expected 1.5, got %f (err=%v)", value, err)
+	}
+	if value := cache.GetValue("float32"); value != float32(1.5) {
+		t.Errorf("expected value to remain a float32, got %T", value)
+	}
+	cache.Set("int", 1)
+	if value, err := cache.IncrementFloat("int", 0.5); err != nil || value != 1.5 {
+		t.Errorf("expected 1.5, got %f (err=%v)", value, err)
+	}
+	if value := cache.GetValue("int"); value != 1.5 {
+		t.Errorf("expected value to have been converted to a float64, got %T", value)
+	}
+}
+
+func TestCache_IncrementWhenWriteIsRejected(t *testing.T) {
+	cache := NewCache().WithAdmissionFunc(func(key string, value any) bool {
+		return key != "rejected"
+	})
+	if _, err := cache.Increment("rejected", 1); err != ErrAdmissionRejected {
+		t.Errorf("expected %v, got %v", ErrAdmissionRejected, err)
+	}
+	if _, err := cache.IncrementFloat("rejected", 1); err != ErrAdmissionRejected {
+		t.Errorf("expected %v, got %v", ErrAdmissionRejected, err)
+	}
+	if _, exists := cache.Get("rejected"); exists {
+		t.Error("expected the rejected key not to have been created")
+	}
+	cache.WithAdmissionFunc(nil)
+	cache.Set("rejected", 1)
+	cache.WithAdmissionFunc(func(key string, value any) bool {
+		return key != "rejected"
+	})
+	if _, err := cache.Increment("rejected", 1); err != ErrAdmissionRejected {
+		t.Errorf("expected %v, got %v", ErrAdmissionRejected, err)
+	}
+	if value := cache.GetValue("rejected"); value != 1 {
+		t.Errorf("expected the value to have been left unchanged, got %v", value)
+	}
+}
+
+func TestCache_IncrementIsAtomic(t *testing.T) {
+	cache := NewCache()
+	var wg sync.WaitGroup
+	for i := 0; i < 100; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, _ = cache.Increment("counter", 1)
+		}()
+	}
+	wg.Wait()
+	if value := cache.GetValue("counter"); value != int64(100) {
+		t.Errorf("expected 100, got %v", value)
+	}
+}
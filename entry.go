@@ -1,7 +1,7 @@
 package gocache
 
 import (
-	"fmt"
+	"reflect"
 	"time"
 	"unsafe"
 )
@@ -24,10 +24,97 @@ type Entry struct {
 	// Expiration is the unix time in nanoseconds at which the entry will expire (-1 means no expiration)
 	Expiration int64
 
+	// Version is incremented every time the entry is written to (i.e. Set, SetWithTTL, Increment, Expire, etc.), and can
+	// be used with Cache.SetIfVersion to detect concurrent modifications.
+	//
+	// Versions are drawn from a counter shared by the entire cache, which means that they are unique across keys, and
+	// that a key that is deleted and created again never ends up with a version that it had before.
+	Version uint64
+
+	// size is the size of the entry computed when its value was last written, which is what the memory usage of the
+	// cache and of its quotas are based on, or 0 if the cache does not keep track of the size of its entries
+	size int
+
+	// cost is the cost of the entry, which is what the total cost of the cache is based on (see Cache.WithMaxCost)
+	cost int
+
+	// order is a number assigned in increasing order every time the entry is put at the head, which means that the
+	// entries are always sorted by order from the tail to the head
+	order uint64
+
+	// createdAt is the unix time in nanoseconds at which the entry was created
+	createdAt int64
+
+	// accessedAt is the unix time in nanoseconds at which the entry was last accessed (e.g. Get), or 0 if it never was
+	accessedAt int64
+
+	// accessCount is the number of times the entry was accessed
+	accessCount uint64
+
+	// metadata is the state of the entry used by optional features, which is only allocated for entries that need
+	// it, so that the entries of a cache that doesn't use these features stay small
+	metadata *entryMetadata
+
 	next     *Entry
 	previous *Entry
 }
 
+// entryMetadata is the state of an entry used by optional features
+type entryMetadata struct {
+	// tags are the tags associated with the entry through Cache.SetWithTags
+	tags []string
+
+	// namespace is the Namespace the entry is part of, if any
+	namespace *Namespace
+
+	// quota is the quota the entry counts toward, if any
+	quota *quota
+
+	// quotaNext and quotaPrevious link the entry to the other entries that count toward the same quota, in the same
+	// order as next and previous
+	quotaNext     *Entry
+	quotaPrevious *Entry
+
+	// rule is the rule that applies to the entry, if any
+	rule *rule
+
+	// id is a number that uniquely identifies the entry, which is assigned in increasing order when the entry is
+	// added to the scan index
+	id uint64
+}
+
+// meta returns the entry's metadata, allocating it if the entry has none yet
+func (entry *Entry) meta() *entryMetadata {
+	if entry.metadata == nil {
+		entry.metadata = &entryMetadata{}
+	}
+	return entry.metadata
+}
+
+// namespace returns the Namespace the entry is part of, or nil if there is none
+func (entry *Entry) namespace() *Namespace {
+	if entry.metadata == nil {
+		return nil
+	}
+	return entry.metadata.namespace
+}
+
+// quota returns the quota the entry counts toward, or nil if there is none
+func (entry *Entry) quota() *quota {
+	if entry.metadata == nil {
+		return nil
+	}
+	return entry.metadata.quota
+}
+
+// rule returns the rule that applies to the entry, or nil if there is none
+func (entry *Entry) rule() *rule {
+	if entry.metadata == nil {
+		return nil
+	}
+	return entry.metadata.rule
+}
+
 // Accessed updates the Entry's RelevantTimestamp to now
 func (entry *Entry) Accessed() {
 	entry.RelevantTimestamp = time.Now()
@@ -44,11 +131,21 @@ func (entry Entry) Expired() bool {
 }
 
 // SizeInBytes returns the size of an entry in bytes, approximately.
+//
+// If the value implements Sizer, the size it reports is used. Otherwise, the size of the value is estimated by
+// walking it, which is fast for native types (string, int, []byte, etc.), but may be slow for large structs and maps.
+//
+// Note that this does not take the function passed to Cache.WithSizeFunc into account.
 func (entry *Entry) SizeInBytes() int {
-	return toBytes(entry.Key) + toBytes(entry.Value) + 32
+	return toBytes(entry.Key) + toBytes(entry.Value) + entryOverheadInBytes
 }
 
 func toBytes(value any) int {
+	// Like referencedSize, nil pointers are never asked for their size, as calling SizeInBytes on a nil pointer
+	// panics if the method has a value receiver
+	if sizer, ok := value.(Sizer); ok && !isNilPointer(value) {
+		return int(unsafe.Sizeof(value)) + sizer.SizeInBytes()
+	}
 	switch value.(type) {
 	case string:
 		return int(unsafe.Sizeof(value)) + len(value.(string))
@@ -103,6 +200,12 @@ func toBytes(value any) int {
 	case []complex128:
 		return int(unsafe.Sizeof(value)) + (len(value.([]complex128)) * 8)
 	default:
-		return int(unsafe.Sizeof(value)) + len(fmt.Sprintf("%v", value))
+		return estimateSize(value)
 	}
 }
+
+// isNilPointer returns whether the value passed as parameter is a nil pointer with a non-nil type
+func isNilPointer(value any) bool {
+	v := reflect.ValueOf(value)
+	return v.Kind() == reflect.Pointer && v.IsNil()
+}
@@ -5,6 +5,22 @@ import (
 	"testing"
 )
 
+func TestEntry_metadata(t *testing.T) {
+	cache := NewCache().WithRule(Rule{Pattern: "pinned:*", NoEviction: true})
+	cache.Set("1", "v")
+	if cache.entries["1"].metadata != nil {
+		t.Error("expected entry that doesn't use any optional feature to have no metadata")
+	}
+	cache.Set("pinned:1", "v")
+	if entry := cache.entries["pinned:1"]; entry.metadata == nil || entry.rule() == nil {
+		t.Error("expected entry matching a rule to have metadata")
+	}
+	cache.SetWithTags("2", "v", NoExpiration, "tag")
+	if entry := cache.entries["2"]; entry.metadata == nil || len(entry.metadata.tags) != 1 {
+		t.Error("expected tagged entry to have metadata")
+	}
+}
+
 func TestEntry_SizeInBytes(t *testing.T) {
 	testSizeInBytes(t, "key", 0, 75)
 	testSizeInBytes(t, "k", 0, 73)
@@ -58,11 +74,11 @@ func TestEntry_SizeInBytes(t *testing.T) {
 	testSizeInBytes(t, "k", []float64{1, 2}, 81)
 	testSizeInBytes(t, "k", []complex128{1}, 73)
 	testSizeInBytes(t, "k", []complex128{1, 2}, 81)
-	testSizeInBytes(t, "k", struct{}{}, 67)
-	testSizeInBytes(t, "k", struct{ A string }{A: "hello"}, 72)
-	testSizeInBytes(t, "k", struct{ A, B string }{A: "hello", B: "world"}, 78)
-	testSizeInBytes(t, "k", nil, 70)
-	testSizeInBytes(t, "k", make([]any, 5), 170)
+	testSizeInBytes(t, "k", struct{}{}, 65)
+	testSizeInBytes(t, "k", struct{ A string }{A: "hello"}, 86)
+	testSizeInBytes(t, "k", struct{ A, B string }{A: "hello", B: "world"}, 107)
+	testSizeInBytes(t, "k", nil, 65)
+	testSizeInBytes(t, "k", make([]any, 5), 145)
 }
 
 func testSizeInBytes(t *testing.T, key string, value any, expectedSize int) {
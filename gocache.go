@@ -3,7 +3,9 @@ package gocache
 import (
 	"errors"
 	"reflect"
+	"regexp"
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
@@ -31,9 +33,12 @@ const (
 )
 
 var (
-	ErrKeyDoesNotExist       = errors.New("key does not exist")         // Returned when a cache key does not exist
-	ErrKeyHasNoExpiration    = errors.New("key has no expiration")      // Returned when a cache key has no expiration
-	ErrJanitorAlreadyRunning = errors.New("janitor is already running") // Returned when the janitor has already been started
+	ErrKeyDoesNotExist       = errors.New("key does not exist")                         // Returned when a cache key does not exist
+	ErrKeyHasNoExpiration    = errors.New("key has no expiration")                      // Returned when a cache key has no expiration
+	ErrJanitorAlreadyRunning = errors.New("janitor is already running")                 // Returned when the janitor has already been started
+	ErrInvalidTTL            = errors.New("ttl must be greater than 0 or NoExpiration") // Returned when a TTL is neither greater than 0 nor NoExpiration
+	ErrValueTooLarge         = errors.New("value is too large")                         // Returned when an entry is larger than the maximum entry size
+	ErrAdmissionRejected     = errors.New("admission rejected")                         // Returned when a write is rejected by the cost limit or the admission function
 )
 
 // Cache is the core struct of gocache which contains the data as well as all relevant configuration fields
@@ -77,6 +82,38 @@ type Cache struct {
 	// memoryUsage is the approximate memory usage of the cache (dataset only) in bytes
 	memoryUsage int
 
+	// memoryPressureMonitor is the state of the memory pressure monitor, which is nil unless started through
+	// StartMemoryPressureMonitor
+	memoryPressureMonitor *memoryPressureMonitor
+
+	// memoryLimitChangeReason is the reason why the memory pressure monitor last changed maxMemoryUsage, if it did
+	memoryLimitChangeReason MemoryLimitChangeReason
+
+	// budget is the Budget the cache is part of, if any
+	budget *Budget
+
+	// maxCost is the maximum total cost of the entries in the cache at any given time
+	// By default, this is set to NoMaxCost, meaning that the default behavior is to not evict based on cost
+	maxCost int
+
+	// totalCost is the sum of the cost of every entry in the cache
+	totalCost int
+
+	// maxEntrySize is the maximum size of a single entry
+	// By default, this is set to NoMaxEntrySize, meaning that only MaxMemoryUsage applies
+	maxEntrySize int
+
+	// maxEntrySizeRatio is the maximum size of a single entry as a fraction of maxMemoryUsage, or 0 if there is none
+	maxEntrySizeRatio float64
+
+	// admit is the function used to decide whether a write may take place
+	// Defaults to nil, meaning that every write is admitted
+	admit func(key string, value any) bool
+
+	// costFunc is the function used to compute the cost of the entries written without specifying a cost
+	// Defaults to nil, meaning that every entry has a cost of DefaultCost
+	costFunc func(key string, value any) int
+
 	// forceNilInterfaceOnNilPointer determines whether all Set-like functions should set a value as nil if the
 	// interface passed has a nil value but not a nil type.
 	//
@@ -85,6 +122,79 @@ type Cache struct {
 	// will still show as nil, which means that if you don't cast the interface after
 	// retrieving it, a nil check will return that the value is not false.
 	forceNilInterfaceOnNilPointer bool
+
+	// equal is the function used by CompareAndSwap to compare values
+	// Defaults to reflect.DeepEqual
+	equal func(a, b any) bool
+
+	// sizeFunc is the function used to compute the size of the values
+	// Defaults to nil, meaning that Entry.SizeInBytes is used
+	sizeFunc func(value any) int
+
+	// onEvicted is the function called whenever an entry is removed from the cache
+	onEvicted func(key string, value any, reason RemovalReason)
+
+	// pendingRemovals are the removals that have yet to be passed to onEvicted
+	//
+	// Because onEvicted is called outside the lock, removals are queued while the lock is held and
+	// flushed by Cache.unlock
+	pendingRemovals []removal
+
+	// subscriptions are the subscriptions created through Subscribe
+	//
+	// This slice must never be modified in place, as a copy of it is used to publish events outside the lock
+	subscriptions []*subscription
+
+	// pendingEvents are the events that have yet to be published to the subscriptions
+	pendingEvents []Event
+
+	// subscriptionBufferSize is the size of the buffer of each new subscription
+	subscriptionBufferSize int
+
+	// subscriberOverflowPolicy dictates what happens when a subscription's buffer is full
+	subscriberOverflowPolicy SubscriberOverflowPolicy
+
+	// changeLog is the bounded log of every mutation made to the cache
+	// Defaults to nil, meaning that mutations are not recorded
+	changeLog *changeLog
+
+	// lastVersion is the version assigned to the entry most recently written
+	lastVersion uint64
+
+	// waiters are the goroutines waiting for a key to be set through WaitFor, indexed by key
+	waiters map[string]*waiter
+
+	// tags is the inverted index of the tags set through SetWithTags, mapping each tag to the keys associated with it
+	tags map[string]map[string]struct{}
+
+	// namespaces are the namespaces created through Namespace, indexed by name
+	namespaces map[string]*Namespace
+
+	// quotas are the quotas set through WithQuota, in the order in which they were added
+	quotas []*quota
+
+	// rules are the rules added through WithRule, in order of precedence
+	rules []*rule
+
+	// pinned is the number of entries that may not be evicted (see Rule.NoEviction)
+	pinned int
+
+	// prefixIndex is the index of the keys ordered by prefix, which is nil unless enabled through WithPrefixIndex
+	prefixIndex *prefixIndex
+
+	// patternDialect is the dialect of the patterns used to match keys
+	// Defaults to FilepathGlob
+	patternDialect PatternDialect
+
+	// lastID is the id assigned to the entry most recently added to the scan index
+	lastID uint64
+
+	// scanIndex is the list of every entry ordered by id, which is used by Scan and the iterators, and which is nil
+	// unless enabled through WithScanIndex or by using Scan or one of the iterators
+	scanIndex *scanIndex
+
+	// lastOrder is the order assigned to the entry most recently put at the head
+	lastOrder uint64
 }
 
 // MaxSize returns the maximum amount of keys that can be present in the cache before
@@ -93,8 +203,11 @@ func (cache *Cache) MaxSize() int {
 	return cache.maxSize
 }
 
-// MaxMemoryUsage returns the configured maxMemoryUsage of the cache
+// MaxMemoryUsage returns the configured maxMemoryUsage of the cache, or the effective maxMemoryUsage if the memory
+// pressure monitor is running (see StartMemoryPressureMonitor)
 func (cache *Cache) MaxMemoryUsage() int {
+	cache.mutex.RLock()
+	defer cache.mutex.RUnlock()
 	return cache.maxMemoryUsage
 }
 
@@ -111,13 +224,20 @@ func (cache *Cache) Stats() Statistics {
 		ExpiredKeys: cache.stats.ExpiredKeys,
 		Hits:        cache.stats.Hits,
 		Misses:      cache.stats.Misses,
+		TotalCost:   cache.totalCost,
+		EvictedCost: cache.stats.EvictedCost,
+
+		EffectiveMaxMemoryUsage: cache.maxMemoryUsage,
+		MemoryLimitChangeReason: cache.memoryLimitChangeReason,
 	}
+	// DroppedEvents is incremented outside the lock, see subscription.publish
+	stats.DroppedEvents = atomic.LoadUint64(&cache.stats.DroppedEvents)
 	cache.mutex.RUnlock()
 	return stats
 }
 
 // MemoryUsage returns the current memory usage of the cache's dataset in bytes
-// If MaxMemoryUsage is set to NoMaxMemoryUsage, this will return 0
+// If MaxMemoryUsage is set to NoMaxMemoryUsage and the cache is not part of a Budget, this will return 0
 func (cache *Cache) MemoryUsage() int {
 	cache.mutex.RLock()
 	defer cache.mutex.RUnlock()
@@ -141,12 +261,20 @@ func (cache *Cache) WithMaxSize(maxSize int) *Cache {
 //
 // NOTE: This is approximate.
 //
+// If the cache already has entries, they are evicted until the cache no longer exceeds the new limit.
 // Setting this to NoMaxMemoryUsage will disable eviction by memory usage
 func (cache *Cache) WithMaxMemoryUsage(maxMemoryUsageInBytes int) *Cache {
 	if maxMemoryUsageInBytes < 0 {
 		maxMemoryUsageInBytes = NoMaxMemoryUsage
 	}
+	cache.mutex.Lock()
+	defer cache.unlock()
 	cache.maxMemoryUsage = maxMemoryUsageInBytes
+	if len(cache.entries) > 0 {
+		// The size of the existing entries may not have been tracked until now
+		cache.recalculateMemoryUsage()
+		cache.evictIfNecessary()
+	}
 	return cache
 }
 
@@ -212,6 +340,37 @@ func (cache *Cache) WithForceNilInterfaceOnNilPointer(forceNilInterfaceOnNilPoin
 	return cache
 }
 
+// WithPatternDialect sets the dialect of the patterns used to match keys, which applies to GetKeysByPattern,
+// DeleteKeysByPattern, Subscribe, WithQuota and WithRule.
+//
+// Rules, quotas and subscriptions keep the dialect that was configured when they were added, so this should be
+// called before any of them are added.
+//
+// Defaults to FilepathGlob
+func (cache *Cache) WithPatternDialect(dialect PatternDialect) *Cache {
+	cache.mutex.Lock()
+	cache.patternDialect = dialect
+	cache.mutex.Unlock()
+	return cache
+}
+
+// WithOnEvicted sets the function to call whenever an entry is removed from the cache, along with the reason
+// for the removal (Evicted, Expired, Deleted, Replaced or Cleared).
+//
+// The function is called after the cache's lock has been released, which means that it is safe for the function
+// to call the cache's functions. Note that the function is called synchronously by the goroutine that caused the
+// removal, so a slow function will slow down the operation that triggered it.
+//
+//	cache := gocache.NewCache().WithOnEvicted(func(key string, value any, reason gocache.RemovalReason) {
+//		if reason != gocache.Replaced {
+//			value.(io.Closer).Close()
+//		}
+//	})
+func (cache *Cache) WithOnEvicted(onEvicted func(key string, value any, reason RemovalReason)) *Cache {
+	cache.onEvicted = onEvicted
+	return cache
+}
+
 // NewCache creates a new Cache
 //
 // Should be used in conjunction with Cache.WithMaxSize, Cache.WithMaxMemoryUsage and/or Cache.WithEvictionPolicy
@@ -227,19 +386,36 @@ func NewCache() *Cache {
 		mutex:                         sync.RWMutex{},
 		stopJanitor:                   nil,
 		forceNilInterfaceOnNilPointer: true,
+		equal:                         reflect.DeepEqual,
+		subscriptionBufferSize:        DefaultSubscriptionBufferSize,
+		subscriberOverflowPolicy:      DropEvents,
+		patternDialect:                FilepathGlob,
 	}
 }
 
-// Set creates or updates a key with a given value
+// Set creates or updates a key with a given value using the default TTL of the rule matching the key (see WithRule),
+// or the cache's default TTL if there is no such rule
 func (cache *Cache) Set(key string, value any) {
-	cache.SetWithTTL(key, value, cache.defaultTTL)
+	cache.SetWithTTL(key, value, useDefaultTTL)
 }
 
 // SetWithTTL creates or updates a key with a given value and sets an expiration time (-1 is NoExpiration)
 //
 // The TTL provided must be greater than 0, or NoExpiration (-1). If a negative value that isn't -1 (NoExpiration) is
 // provided, the entry will not be created if the key doesn't exist
+//
+// If the write is rejected (see TrySetWithTTL), the key is deleted, so that its previous value can no longer be
+// retrieved.
 func (cache *Cache) SetWithTTL(key string, value any, ttl time.Duration) {
+	value = cache.normalizeValue(value)
+	cache.mutex.Lock()
+	defer cache.unlock()
+	cache.set(key, value, ttl)
+	cache.evictIfNecessary()
+}
+
+// normalizeValue returns the value that should be stored in the cache for the value passed as parameter
+func (cache *Cache) normalizeValue(value any) any {
 	// An interface is only nil if both its value and its type are nil, however, passing a nil pointer as an interface{}
 	// means that the interface itself is not nil, because the interface value is nil but not the type.
 	if cache.forceNilInterfaceOnNilPointer {
@@ -247,21 +423,97 @@ func (cache *Cache) SetWithTTL(key string, value any, ttl time.Duration) {
 			value = nil
 		}
 	}
-	cache.mutex.Lock()
+	return value
+}
+
+// set creates or updates an entry, but unlike SetWithTTL, it doesn't trigger evictions
+//
+// Returns the entry, or nil if the entry doesn't exist after the operation (i.e. because the TTL was negative) or if
+// the write was rejected (see trySet), in which case the existing entry, if any, is deleted.
+//
+// Must be called while the cache's lock is held.
+func (cache *Cache) set(key string, value any, ttl time.Duration) *Entry {
+	entry, err := cache.trySet(key, value, ttl, useCostFunc)
+	if err != nil {
+		// The previous value must not be returned by reads that follow a write that was supposed to replace it
+		cache.delete(key)
+	}
+	return entry
+}
+
+// trySet creates or updates an entry with a given cost, or with the cost computed by the cost function if the cost
+// is useCostFunc, unless the write is rejected (see checkAdmission), in which case the cache is left unchanged
+//
+// Returns the entry, or nil if the entry doesn't exist after the operation (i.e. because the TTL was negative), or
+// the error explaining why the write was rejected.
+//
+// Must be called while the cache's lock is held. Because this calls user code (e.g. the cost function, the size
+// function, Sizer and the admission function), callers must release the lock with a deferred call, so that a panic
+// doesn't leave the cache locked.
+func (cache *Cache) trySet(key string, value any, ttl time.Duration, cost int) (*Entry, error) {
+	size, cost := cache.measure(key, value, cost)
+	if err := cache.checkAdmission(key, value, size, cost); err != nil {
+		return nil, err
+	}
+	return cache.setWithTTL(key, value, ttl, size, cost), nil
+}
+
+// setWithTTL creates or updates an entry with a given TTL, along with the size and the cost of the entry (see
+// measure), without checking whether the write must be rejected
+//
+// Returns the entry, or nil if the entry doesn't exist after the operation (i.e. because the TTL was negative).
+//
+// Must be called while the cache's lock is held.
+func (cache *Cache) setWithTTL(key string, value any, ttl time.Duration, size, cost int) *Entry {
+	if ttl == useDefaultTTL || len(cache.rules) > 0 {
+		ttl = cache.resolveTTL(key, ttl)
+	}
+	// A negative TTL that isn't -1 (NoExpiration) or 0 is an entry that will expire instantly,
+	// so might as well just not create it in the first place, or delete it immediately if it already exists
+	if ttl != NoExpiration && ttl < 1 {
+		cache.delete(key)
+		return nil
+	}
+	if ttl != NoExpiration {
+		return cache.setWithExpiration(key, value, time.Now().Add(ttl).UnixNano(), size, cost)
+	}
+	return cache.setWithExpiration(key, value, NoExpiration, size, cost)
+}
+
+// measure returns the size of an entry with the key and value passed as parameter, along with its cost, which is the
+// cost passed as parameter, unless it is useCostFunc, in which case it's the cost computed by the cost function
+//
+// The size is only computed if the cache needs it, and is 0 otherwise.
+//
+// Must be called while the cache's lock is held.
+func (cache *Cache) measure(key string, value any, cost int) (int, int) {
+	if cost == useCostFunc {
+		cost = DefaultCost
+		if cache.costFunc != nil {
+			cost = cache.costFunc(key, value)
+		}
+	}
+	size := 0
+	if cache.tracksEntrySizes() || cache.maxEntrySize != NoMaxEntrySize {
+		size = cache.sizeOf(key, value)
+	}
+	return size, max(cost, 0)
+}
+
+// setWithExpiration creates or updates an entry with the unix time in nanoseconds at which the entry will expire
+// (-1 means no expiration), along with the size and the cost of the entry (see measure)
+//
+// Must be called while the cache's lock is held.
+func (cache *Cache) setWithExpiration(key string, value any, expiration int64, size, cost int) *Entry {
 	entry, ok := cache.get(key)
 	if !ok {
-		// A negative TTL that isn't -1 (NoExpiration) or 0 is an entry that will expire instantly,
-		// so might as well just not create it in the first place
-		if ttl != NoExpiration && ttl < 1 {
-			cache.mutex.Unlock()
-			return
-		}
 		// Cache entry doesn't exist, so we have to create a new one
 		entry = &Entry{
 			Key:               key,
 			RelevantTimestamp: time.Now(),
 			next:              cache.head,
 		}
+		entry.createdAt = entry.RelevantTimestamp.UnixNano()
 		entry.Value = value
 		if cache.head == nil {
 			cache.tail = entry
@@ -269,58 +521,84 @@ func (cache *Cache) SetWithTTL(key string, value any, ttl time.Duration) {
 			cache.head.previous = entry
 		}
 		cache.head = entry
+		cache.lastOrder++
+		entry.order = cache.lastOrder
 		cache.entries[key] = entry
-		if cache.maxMemoryUsage != NoMaxMemoryUsage {
-			cache.memoryUsage += entry.SizeInBytes()
+		if len(cache.namespaces) > 0 {
+			cache.addToNamespace(entry)
 		}
-	} else {
-		// A negative TTL that isn't -1 (NoExpiration) or 0 is an entry that will expire instantly,
-		// so might as well just delete it immediately instead of updating it
-		if ttl != NoExpiration && ttl < 1 {
-			cache.delete(key)
-			cache.mutex.Unlock()
-			return
+		if len(cache.quotas) > 0 {
+			cache.addToQuota(entry)
 		}
-		if cache.maxMemoryUsage != NoMaxMemoryUsage {
-			// Subtract the old entry from the cache's memoryUsage
-			cache.memoryUsage -= entry.SizeInBytes()
+		if len(cache.rules) > 0 {
+			if r := cache.matchRule(key); r != nil {
+				cache.setRule(entry, r)
+			}
 		}
+		if cache.prefixIndex != nil {
+			cache.prefixIndex.insert(entry)
+		}
+		if cache.scanIndex != nil {
+			cache.addToScanIndex(entry)
+		}
+		cache.updateSize(entry, size)
+		cache.setCost(entry, cost)
+	} else {
+		cache.queueRemoval(entry, Replaced)
 		entry.Value = value
 		entry.RelevantTimestamp = time.Now()
-		if cache.maxMemoryUsage != NoMaxMemoryUsage {
-			// Add the memory usage of the new entry to the cache's memoryUsage
-			cache.memoryUsage += entry.SizeInBytes()
-		}
+		cache.updateSize(entry, size)
+		cache.setCost(entry, cost)
 		// Because we just updated the entry, we need to move it back to HEAD
 		cache.moveExistingEntryToHead(entry)
 	}
-	if ttl != NoExpiration {
-		entry.Expiration = time.Now().Add(ttl).UnixNano()
-	} else {
-		entry.Expiration = NoExpiration
-	}
-	// If the cache doesn't have a maxSize/maxMemoryUsage, then there's no point
+	entry.Expiration = expiration
+	cache.lastVersion++
+	entry.Version = cache.lastVersion
+	cache.queueEvent(EventSet, entry)
+	cache.wakeWaiters(key, value)
+	return entry
+}
+
+// evictIfNecessary evicts entries until neither the cache nor its quotas exceed their maxSize and maxMemoryUsage
+//
+// Must be called while the cache's lock is held.
+func (cache *Cache) evictIfNecessary() {
+	// Entries that count toward an exceeded quota are evicted first, so that a single group of keys cannot cause
+	// everybody else's entries to be evicted
+	if len(cache.quotas) > 0 {
+		cache.enforceQuotas()
+	}
+	// If the cache doesn't have a maxSize/maxMemoryUsage/maxCost, then there's no point
 	// checking if we need to evict an entry, so we'll just return now
-	if cache.maxSize == NoMaxSize && cache.maxMemoryUsage == NoMaxMemoryUsage {
-		cache.mutex.Unlock()
+	if cache.maxSize == NoMaxSize && cache.maxMemoryUsage == NoMaxMemoryUsage && cache.maxCost == NoMaxCost {
 		return
 	}
 	// If there's a maxSize and the cache has more entries than the maxSize, evict
-	if cache.maxSize != NoMaxSize && len(cache.entries) > cache.maxSize {
-		cache.evict()
+	for cache.maxSize != NoMaxSize && len(cache.entries) > cache.maxSize {
+		if !cache.evict() {
+			return
+		}
 	}
 	// If there's a maxMemoryUsage and the memoryUsage is above the maxMemoryUsage, evict
 	if cache.maxMemoryUsage != NoMaxMemoryUsage && cache.memoryUsage > cache.maxMemoryUsage {
 		for cache.memoryUsage > cache.maxMemoryUsage && len(cache.entries) > 0 {
-			cache.evict()
+			if !cache.evict() {
+				return
+			}
+		}
+	}
+	// If there's a maxCost and the total cost is above the maxCost, evict
+	for cache.maxCost != NoMaxCost && cache.totalCost > cache.maxCost && len(cache.entries) > 0 {
+		if !cache.evict() {
+			return
 		}
 	}
-	cache.mutex.Unlock()
 }
 
 // SetAll creates or updates multiple values
 func (cache *Cache) SetAll(entries map[string]any) {
-	cache.SetAllWithTTL(entries, cache.defaultTTL)
+	cache.SetAllWithTTL(entries, useDefaultTTL)
 }
 
 // SetAllWithTTL creates or updates multiple values
@@ -335,30 +613,44 @@ func (cache *Cache) SetAllWithTTL(entries map[string]any, ttl time.Duration) {
 // If there is an entry, the value returned will be the value cached and the boolean will be true
 func (cache *Cache) Get(key string) (any, bool) {
 	cache.mutex.Lock()
+	entry, ok := cache.access(key)
+	if !ok {
+		cache.unlock()
+		return nil, false
+	}
+	value := entry.Value
+	cache.unlock()
+	return value, true
+}
+
+// access retrieves an entry using the key passed as parameter, and unlike get, it updates the statistics, deletes
+// the entry if it has expired and, if the eviction policy is LRU, moves the entry to the head
+//
+// Must be called while the cache's lock is held.
+func (cache *Cache) access(key string) (*Entry, bool) {
 	entry, ok := cache.get(key)
 	if !ok {
 		cache.stats.Misses++
-		cache.mutex.Unlock()
 		return nil, false
 	}
 	if entry.Expired() {
 		cache.stats.ExpiredKeys++
-		cache.delete(key)
-		cache.mutex.Unlock()
+		cache.remove(entry, Expired)
 		return nil, false
 	}
 	cache.stats.Hits++
+	entry.accessCount++
 	if cache.evictionPolicy == LeastRecentlyUsed {
 		entry.Accessed()
-		if cache.head == entry {
-			cache.mutex.Unlock()
-			return entry.Value, true
+		entry.accessedAt = entry.RelevantTimestamp.UnixNano()
+		if cache.head != entry {
+			// Because the eviction policy is LRU, we need to move the entry back to HEAD
+			cache.moveExistingEntryToHead(entry)
 		}
-		// Because the eviction policy is LRU, we need to move the entry back to HEAD
-		cache.moveExistingEntryToHead(entry)
+	} else {
+		entry.accessedAt = time.Now().UnixNano()
 	}
-	cache.mutex.Unlock()
-	return entry.Value, true
+	return entry, true
 }
 
 // GetValue retrieves an entry using the key passed as parameter
@@ -396,17 +688,17 @@ func (cache *Cache) GetAll() map[string]any {
 	cache.mutex.Lock()
 	for key, entry := range cache.entries {
 		if entry.Expired() {
-			cache.delete(key)
+			cache.remove(entry, Expired)
 			continue
 		}
 		entries[key] = entry.Value
 	}
 	cache.stats.Hits += uint64(len(entries))
-	cache.mutex.Unlock()
+	cache.unlock()
 	return entries
 }
 
-// GetKeysByPattern retrieves a slice of keys that match a given pattern
+// GetKeysByPattern retrieves a slice of keys that match a given pattern (see WithPatternDialect)
 // If the limit is set to 0, the entire cache will be searched for matching keys.
 // If the limit is above 0, the search will stop once the specified number of matching keys have been found.
 //
@@ -418,18 +710,41 @@ func (cache *Cache) GetAll() map[string]any {
 // Note that GetKeysByPattern does not trigger active evictions, nor does it count as accessing the entry (if LRU).
 // The reason for that behavior is that these two (active eviction and access) only applies when you access the value
 // of the cache entry, and this function only returns the keys.
+//
+// If the prefix index is enabled (see WithPrefixIndex) and the pattern starts with a literal prefix (e.g. "some:*"),
+// only the keys starting with that prefix are searched.
 func (cache *Cache) GetKeysByPattern(pattern string, limit int) []string {
 	var matchingKeys []string
 	cache.mutex.Lock()
-	for key, value := range cache.entries {
-		if value.Expired() {
+	cache.forEachEntryMatching(pattern, func(entry *Entry) bool {
+		if entry.Expired() {
+			return true
+		}
+		matchingKeys = append(matchingKeys, entry.Key)
+		return limit <= 0 || len(matchingKeys) < limit
+	})
+	cache.mutex.Unlock()
+	return matchingKeys
+}
+
+// GetKeysByRegexp retrieves a slice of keys that match a given regular expression
+// If the limit is set to 0, the entire cache will be searched for matching keys.
+// If the limit is above 0, the search will stop once the specified number of matching keys have been found.
+//
+// Note that the regular expression is not anchored unless it starts with '^' and ends with '$'. For instance,
+// "user" matches every key containing "user", while "^user:[0-9]+$" only matches keys such as "user:123".
+//
+// Unlike GetKeysByPattern, this always goes through every key of the cache, even if the prefix index is enabled.
+func (cache *Cache) GetKeysByRegexp(re *regexp.Regexp, limit int) []string {
+	var matchingKeys []string
+	cache.mutex.Lock()
+	for key, entry := range cache.entries {
+		if entry.Expired() || !re.MatchString(key) {
 			continue
 		}
-		if MatchPattern(pattern, key) {
-			matchingKeys = append(matchingKeys, key)
-			if limit > 0 && len(matchingKeys) >= limit {
-				break
-			}
+		matchingKeys = append(matchingKeys, key)
+		if limit > 0 && len(matchingKeys) >= limit {
+			break
 		}
 	}
 	cache.mutex.Unlock()
@@ -442,7 +757,7 @@ func (cache *Cache) GetKeysByPattern(pattern string, limit int) []string {
 func (cache *Cache) Delete(key string) bool {
 	cache.mutex.Lock()
 	ok := cache.delete(key)
-	cache.mutex.Unlock()
+	cache.unlock()
 	return ok
 }
 
@@ -457,7 +772,7 @@ func (cache *Cache) DeleteAll(keys []string) int {
 			numberOfKeysDeleted++
 		}
 	}
-	cache.mutex.Unlock()
+	cache.unlock()
 	return numberOfKeysDeleted
 }
 
@@ -468,6 +783,14 @@ func (cache *Cache) DeleteKeysByPattern(pattern string) int {
 	return cache.DeleteAll(cache.GetKeysByPattern(pattern, 0))
 }
 
+// DeleteKeysByRegexp deletes all entries whose key matches a given regular expression and returns the number of
+// entries deleted.
+//
+// See GetKeysByRegexp
+func (cache *Cache) DeleteKeysByRegexp(re *regexp.Regexp) int {
+	return cache.DeleteAll(cache.GetKeysByRegexp(re, 0))
+}
+
 // Count returns the total amount of entries in the cache, regardless of whether they're expired or not
 func (cache *Cache) Count() int {
 	cache.mutex.RLock()
@@ -479,11 +802,32 @@ func (cache *Cache) Count() int {
 // Clear deletes all entries from the cache
 func (cache *Cache) Clear() {
 	cache.mutex.Lock()
+	if cache.onEvicted != nil || len(cache.subscriptions) > 0 || cache.changeLog != nil {
+		for _, entry := range cache.entries {
+			cache.queueRemoval(entry, Cleared)
+		}
+	}
 	cache.entries = make(map[string]*Entry)
-	cache.memoryUsage = 0
+	cache.tags = nil
+	for _, ns := range cache.namespaces {
+		ns.entries = make(map[string]*Entry)
+	}
+	for _, q := range cache.quotas {
+		q.count, q.memoryUsage, q.pinned = 0, 0, 0
+		q.head, q.tail = nil, nil
+	}
+	cache.pinned = 0
+	if cache.prefixIndex != nil {
+		cache.prefixIndex = &prefixIndex{}
+	}
+	if cache.scanIndex != nil {
+		cache.scanIndex = &scanIndex{}
+	}
+	cache.addMemoryUsage(-cache.memoryUsage)
+	cache.totalCost = 0
 	cache.head = nil
 	cache.tail = nil
-	cache.mutex.Unlock()
+	cache.unlock()
 }
 
 // TTL returns the time until the cache entry specified by the key passed as parameter
@@ -512,6 +856,8 @@ func (cache *Cache) TTL(key string) (time.Duration, error) {
 // A TTL of -1 means that the key will never expire
 // A TTL of 0 means that the key will expire immediately
 // If using LRU, note that this does not reset the position of the key
+// If a rule with a MaxTTL applies to the key, the TTL is shortened to the MaxTTL if necessary (see WithRule)
+// The new expiration time is recorded in the change log and published to the subscribers as an EventSet
 //
 // Returns true if the cache key exists and has had its expiration time altered
 func (cache *Cache) Expire(key string, ttl time.Duration) bool {
@@ -521,12 +867,18 @@ func (cache *Cache) Expire(key string, ttl time.Duration) bool {
 		cache.mutex.Unlock()
 		return false
 	}
+	if len(cache.rules) > 0 {
+		ttl = cache.resolveTTL(key, ttl)
+	}
 	if ttl != NoExpiration {
 		entry.Expiration = time.Now().Add(ttl).UnixNano()
 	} else {
 		entry.Expiration = NoExpiration
 	}
-	cache.mutex.Unlock()
+	cache.lastVersion++
+	entry.Version = cache.lastVersion
+	cache.queueEvent(EventSet, entry)
+	cache.unlock()
 	return true
 }
 
@@ -537,18 +889,40 @@ func (cache *Cache) get(key string) (*Entry, bool) {
 	return entry, ok
 }
 
+// delete removes the entry with the key passed as parameter from the cache
+//
+// Returns false if the key did not exist.
 func (cache *Cache) delete(key string) bool {
 	entry, ok := cache.entries[key]
 	if ok {
-		if cache.maxMemoryUsage != NoMaxMemoryUsage {
-			cache.memoryUsage -= entry.SizeInBytes()
-		}
-		cache.removeExistingEntryReferences(entry)
-		delete(cache.entries, key)
+		cache.remove(entry, Deleted)
 	}
 	return ok
 }
 
+// remove removes an existing entry from the cache and queues its removal for the onEvicted callback
+func (cache *Cache) remove(entry *Entry, reason RemovalReason) {
+	if cache.tracksMemoryUsage() {
+		cache.addMemoryUsage(-entry.size)
+	}
+	cache.totalCost -= entry.cost
+	if !entry.evictable() {
+		cache.pinned--
+	}
+	cache.removeExistingEntryReferences(entry)
+	delete(cache.entries, entry.Key)
+	cache.untag(entry)
+	cache.removeFromNamespace(entry, reason)
+	cache.removeFromQuota(entry)
+	if cache.prefixIndex != nil {
+		cache.prefixIndex.delete(entry.Key)
+	}
+	if cache.scanIndex != nil {
+		cache.removeFromScanIndex(entry)
+	}
+	cache.queueRemoval(entry, reason)
+}
+
 // moveExistingEntryToHead replaces the current cache head for an existing entry
 func (cache *Cache) moveExistingEntryToHead(entry *Entry) {
 	if !(entry == cache.head && entry == cache.tail) {
@@ -562,6 +936,11 @@ func (cache *Cache) moveExistingEntryToHead(entry *Entry) {
 		}
 		cache.head = entry
 	}
+	if q := entry.quota(); q != nil {
+		q.moveToHead(entry)
+	}
+	cache.lastOrder++
+	entry.order = cache.lastOrder
 }
 
 // removeExistingEntryReferences modifies the next and previous reference of an existing entry and re-links
@@ -586,18 +965,23 @@ func (cache *Cache) removeExistingEntryReferences(entry *Entry) {
 	entry.previous = nil
 }
 
-// evict removes the tail from the cache
-func (cache *Cache) evict() {
-	if cache.tail == nil || len(cache.entries) == 0 {
-		return
+// evict removes the entry closest to the tail from the cache
+//
+// Entries that may not be evicted (see Rule.NoEviction) are skipped without being moved, so that the order of every
+// other entry is left untouched.
+//
+// Returns false if there was no entry that could be evicted.
+func (cache *Cache) evict() bool {
+	if cache.pinned >= len(cache.entries) {
+		return false
 	}
-	if cache.tail != nil {
-		oldTail := cache.tail
-		cache.removeExistingEntryReferences(oldTail)
-		delete(cache.entries, oldTail.Key)
-		if cache.maxMemoryUsage != NoMaxMemoryUsage {
-			cache.memoryUsage -= oldTail.SizeInBytes()
+	for current := cache.tail; current != nil; current = current.previous {
+		if current.evictable() {
+			cache.stats.EvictedCost += uint64(current.cost)
+			cache.remove(current, Evicted)
+			cache.stats.EvictedKeys++
+			return true
 		}
-		cache.stats.EvictedKeys++
 	}
+	return false
 }
@@ -249,3 +249,55 @@ func BenchmarkCache_WithForceNilInterfaceOnNilPointerWithConcurrency(b *testing.
 		}
 	}
 }
+
+func BenchmarkCache_InvalidateTag(b *testing.B) {
+	cache := NewCache().WithMaxSize(NoMaxSize)
+	for i := 0; i < 100000; i++ {
+		cache.Set("untagged:"+strconv.Itoa(i), "value")
+	}
+	b.ResetTimer()
+	for n := 0; n < b.N; n++ {
+		cache.SetWithTags("tagged:1", "value", NoExpiration, "tag")
+		cache.SetWithTags("tagged:2", "value", NoExpiration, "tag")
+		cache.InvalidateTag("tag")
+	}
+	b.ReportAllocs()
+}
+
+func BenchmarkCache_GetKeysByPattern(b *testing.B) {
+	for _, withPrefixIndex := range []bool{false, true} {
+		b.Run(fmt.Sprintf("withPrefixIndex=%v", withPrefixIndex), func(b *testing.B) {
+			cache := NewCache().WithMaxSize(NoMaxSize).WithPrefixIndex(withPrefixIndex)
+			for i := 0; i < 100000; i++ {
+				cache.Set("tenant:"+strconv.Itoa(i%100)+":"+strconv.Itoa(i), "value")
+			}
+			b.ResetTimer()
+			for n := 0; n < b.N; n++ {
+				cache.GetKeysByPattern("tenant:42:*", 0)
+			}
+			b.ReportAllocs()
+		})
+	}
+}
+
+func BenchmarkCache_SetWithPrefixIndex(b *testing.B) {
+	cache := NewCache().WithMaxSize(10000).WithPrefixIndex(true)
+	for n := 0; n < b.N; n++ {
+		cache.Set("tenant:"+strconv.Itoa(n%100)+":"+strconv.Itoa(n), "value")
+	}
+	b.ReportAllocs()
+}
+
+func BenchmarkEntry_SizeInBytesWithStruct(b *testing.B) {
+	type user struct {
+		ID    int
+		Name  string
+		Email string
+		Roles []string
+	}
+	entry := &Entry{Key: "user:1", Value: user{ID: 1, Name: "John Doe", Email: "john.doe@example.com", Roles: []string{"admin", "user"}}}
+	for n := 0; n < b.N; n++ {
+		entry.SizeInBytes()
+	}
+	b.ReportAllocs()
+}
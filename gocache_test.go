@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"fmt"
 	"math/rand"
+	"regexp"
 	"strconv"
 	"strings"
 	"testing"
@@ -193,6 +194,41 @@ func TestCache_GetKeysByPatternWithExpiredKey(t *testing.T) {
 	}
 }
 
+func TestCache_GetKeysByRegexp(t *testing.T) {
+	cache := NewCache()
+	cache.Set("user:1", "john")
+	cache.Set("user:22", "jane")
+	cache.Set("user:abc", "bob")
+	cache.Set("users/1/profile", "alice")
+	cache.SetWithTTL("user:3", "expired", time.Nanosecond)
+	time.Sleep(time.Millisecond)
+	if keys := cache.GetKeysByRegexp(regexp.MustCompile(`^user:[0-9]+$`), 0); len(keys) != 2 {
+		t.Errorf("expected 2 keys to match, got %v", keys)
+	}
+	if keys := cache.GetKeysByRegexp(regexp.MustCompile(`user`), 0); len(keys) != 4 {
+		t.Errorf("expected unanchored regular expression to match 4 keys, got %v", keys)
+	}
+	if keys := cache.GetKeysByRegexp(regexp.MustCompile(`^users/.+/profile$`), 0); len(keys) != 1 {
+		t.Errorf("expected 1 key to match, got %v", keys)
+	}
+	if keys := cache.GetKeysByRegexp(regexp.MustCompile(`user`), 2); len(keys) != 2 {
+		t.Errorf("expected limit to be respected, got %v", keys)
+	}
+}
+
+func TestCache_DeleteKeysByRegexp(t *testing.T) {
+	cache := NewCache()
+	cache.Set("user:1", "john")
+	cache.Set("user:22", "jane")
+	cache.Set("user:abc", "bob")
+	if numberOfDeletedKeys := cache.DeleteKeysByRegexp(regexp.MustCompile(`^user:\d+$`)); numberOfDeletedKeys != 2 {
+		t.Errorf("expected 2 keys to have been deleted, got %d", numberOfDeletedKeys)
+	}
+	if _, exists := cache.Get("user:abc"); !exists {
+		t.Error("expected key user:abc to still exist")
+	}
+}
+
 func TestCache_Set(t *testing.T) {
 	cache := NewCache().WithMaxSize(NoMaxSize)
 	cache.Set("key", "value")
@@ -0,0 +1,152 @@
+package gocache
+
+import "time"
+
+// EntryInfo is the information about an entry returned by Cache.Inspect
+type EntryInfo struct {
+	// Key is the key of the entry
+	Key string
+
+	// Value is the value of the entry
+	Value any
+
+	// SizeInBytes is the approximate size of the entry (see Entry.SizeInBytes). If the cache keeps track of the size
+	// of its entries (e.g. it has a MaxMemoryUsage), this is the size that counts toward its memory usage, which was
+	// computed when the value was last written (see Cache.RecalculateMemoryUsage).
+	SizeInBytes int
+
+	// Cost is the cost of the entry (see Cache.WithMaxCost)
+	Cost int
+
+	// Version is the version of the entry (see Entry.Version)
+	Version uint64
+
+	// CreatedAt is the time at which the entry was created
+	CreatedAt time.Time
+
+	// LastAccessedAt is the time at which the entry was last accessed, or the zero time if it never was
+	LastAccessedAt time.Time
+
+	// AccessCount is the number of times the entry was accessed
+	AccessCount uint64
+
+	// ExpiresAt is the time at which the entry expires, or the zero time if the entry has no expiration
+	ExpiresAt time.Time
+
+	// Expired is whether the entry has expired, but has not been removed yet
+	Expired bool
+
+	// PositionFromTail is the number of entries between the entry and the tail, which is where entries are evicted
+	// from. In other words, the tail has a PositionFromTail of 0.
+	PositionFromTail int
+}
+
+// Peek retrieves the value of an entry using the key passed as parameter without any side effect, which means that
+// unlike Get, it does not count as accessing the entry (i.e. the entry is not moved to the head if the eviction
+// policy is LeastRecentlyUsed), it does not affect the statistics, and it does not delete the entry if it has
+// expired.
+//
+// Like Get, it returns false if the entry has expired.
+func (cache *Cache) Peek(key string) (any, bool) {
+	cache.mutex.RLock()
+	defer cache.mutex.RUnlock()
+	entry, ok := cache.get(key)
+	if !ok || entry.Expired() {
+		return nil, false
+	}
+	return entry.Value, true
+}
+
+// Inspect retrieves information about an entry using the key passed as parameter, which is mostly useful for
+// debugging purposes (e.g. to understand why an entry was evicted).
+//
+// Like Peek, Inspect has no side effect. Unlike Peek, Inspect also returns entries that have expired, but have not
+// been removed yet.
+//
+// Note that because computing EntryInfo.PositionFromTail requires going through every entry between the tail and the
+// entry, this runs in time proportional to the number of such entries.
+func (cache *Cache) Inspect(key string) (EntryInfo, bool) {
+	cache.mutex.RLock()
+	defer cache.mutex.RUnlock()
+	entry, ok := cache.get(key)
+	if !ok {
+		return EntryInfo{}, false
+	}
+	info := cache.entryInfo(entry)
+	for current := cache.tail; current != nil && current != entry; current = current.previous {
+		info.PositionFromTail++
+	}
+	return info, true
+}
+
+// entryInfo returns the information about an entry, except for its PositionFromTail
+//
+// Must be called while the cache's lock is held.
+func (cache *Cache) entryInfo(entry *Entry) EntryInfo {
+	info := EntryInfo{
+		Key:         entry.Key,
+		Value:       entry.Value,
+		SizeInBytes: entry.size,
+		Cost:        entry.cost,
+		Version:     entry.Version,
+		CreatedAt:   time.Unix(0, entry.createdAt),
+		AccessCount: entry.accessCount,
+		Expired:     entry.Expired(),
+	}
+	if !cache.tracksEntrySizes() {
+		info.SizeInBytes = cache.sizeOf(entry.Key, entry.Value)
+	}
+	if entry.accessedAt != 0 {
+		info.LastAccessedAt = time.Unix(0, entry.accessedAt)
+	}
+	if entry.Expiration != NoExpiration {
+		info.ExpiresAt = time.Unix(0, entry.Expiration)
+	}
+	return info
+}
+
+// NextEvictionCandidates retrieves information about the next n entries that would be evicted if the cache exceeded
+// its MaxSize or MaxMemoryUsage, starting with the entry that would be evicted first. Entries that may not be evicted
+// (see Rule.NoEviction) are skipped. Note that entries counting toward an exceeded quota are evicted first regardless
+// of this order (see WithQuota).
+//
+// Like Inspect, NextEvictionCandidates has no side effect, and includes entries that have expired, but have not been
+// removed yet, since they may be evicted as well.
+func (cache *Cache) NextEvictionCandidates(n int) []EntryInfo {
+	cache.mutex.RLock()
+	defer cache.mutex.RUnlock()
+	candidates := make([]EntryInfo, 0, min(max(n, 0), len(cache.entries)))
+	position := 0
+	for current := cache.tail; current != nil && len(candidates) < n; current = current.previous {
+		if current.evictable() {
+			info := cache.entryInfo(current)
+			info.PositionFromTail = position
+			candidates = append(candidates, info)
+		}
+		position++
+	}
+	return candidates
+}
+
+// EvictionDistance returns the number of entries that would have to be evicted before the entry with the key passed
+// as parameter would be evicted, which means that the entry returned first by NextEvictionCandidates has an eviction
+// distance of 0.
+//
+// Returns false if the key does not exist, or if the entry may not be evicted (see Rule.NoEviction).
+//
+// Like Inspect, this runs in time proportional to the number of entries between the tail and the entry.
+func (cache *Cache) EvictionDistance(key string) (int, bool) {
+	cache.mutex.RLock()
+	defer cache.mutex.RUnlock()
+	entry, ok := cache.get(key)
+	if !ok || !entry.evictable() {
+		return 0, false
+	}
+	distance := 0
+	for current := cache.tail; current != nil && current != entry; current = current.previous {
+		if current.evictable() {
+			distance++
+		}
+	}
+	return distance, true
+}
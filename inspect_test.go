@@ -0,0 +1,147 @@
+package gocache
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCache_Peek(t *testing.T) {
+	cache := NewCache().WithMaxSize(3).WithEvictionPolicy(LeastRecentlyUsed)
+	cache.Set("1", "v1")
+	cache.Set("2", "v2")
+	cache.Set("3", "v3")
+	if value, ok := cache.Peek("1"); !ok || value != "v1" {
+		t.Errorf("expected %s, got %v", "v1", value)
+	}
+	if _, ok := cache.Peek("nope"); ok {
+		t.Error("expected Peek to return false for a key that doesn't exist")
+	}
+	cache.Set("4", "v4")
+	if _, ok := cache.Peek("1"); ok {
+		t.Error("expected 1 to have been evicted, because Peek must not move the entry to the head")
+	}
+	if stats := cache.Stats(); stats.Hits != 0 || stats.Misses != 0 {
+		t.Errorf("expected Peek not to affect the statistics, got %+v", stats)
+	}
+	cache.SetWithTTL("expired", "value", time.Nanosecond)
+	time.Sleep(time.Millisecond)
+	if _, ok := cache.Peek("expired"); ok {
+		t.Error("expected Peek to return false for an expired entry")
+	}
+	if cache.Count() != 3 {
+		t.Errorf("expected Peek not to delete the expired entry, got %d entries", cache.Count())
+	}
+}
+
+func TestCache_Inspect(t *testing.T) {
+	cache := NewCache().WithEvictionPolicy(LeastRecentlyUsed)
+	start := time.Now()
+	cache.Set("1", "v1")
+	cache.SetWithTTL("2", "v2", time.Hour)
+	cache.Set("3", "v3")
+	if info, ok := cache.Inspect("2"); !ok || info.AccessCount != 0 || !info.LastAccessedAt.IsZero() || info.PositionFromTail != 1 {
+		t.Errorf("unexpected info: %+v", info)
+	}
+	cache.Get("2")
+	cache.Get("2")
+	info, ok := cache.Inspect("2")
+	if !ok {
+		t.Fatal("expected 2 to exist")
+	}
+	if info.Key != "2" || info.Value != "v2" || info.SizeInBytes != (&Entry{Key: "2", Value: "v2"}).SizeInBytes() {
+		t.Errorf("unexpected info: %+v", info)
+	}
+	if info.AccessCount != 2 || info.LastAccessedAt.Before(info.CreatedAt) || info.CreatedAt.Before(start) {
+		t.Errorf("unexpected access information: %+v", info)
+	}
+	if info.ExpiresAt.Before(start.Add(59*time.Minute)) || info.Expired {
+		t.Errorf("unexpected expiration information: %+v", info)
+	}
+	if info.PositionFromTail != 2 {
+		t.Errorf("expected 2 to be at the head after being accessed, got position %d", info.PositionFromTail)
+	}
+	if info, _ := cache.Inspect("1"); info.PositionFromTail != 0 || !info.ExpiresAt.IsZero() {
+		t.Errorf("expected 1 to be the tail and to have no expiration, got %+v", info)
+	}
+	if _, ok := cache.Inspect("nope"); ok {
+		t.Error("expected Inspect to return false for a key that doesn't exist")
+	}
+	if stats := cache.Stats(); stats.Hits != 2 {
+		t.Errorf("expected Inspect not to affect the statistics, got %+v", stats)
+	}
+}
+
+func TestCache_InspectExpiredEntry(t *testing.T) {
+	cache := NewCache()
+	cache.SetWithTTL("expired", "value", time.Nanosecond)
+	time.Sleep(time.Millisecond)
+	if info, ok := cache.Inspect("expired"); !ok || !info.Expired {
+		t.Errorf("expected Inspect to return the expired entry, got %+v", info)
+	}
+	if cache.Count() != 1 {
+		t.Error("expected Inspect not to delete the expired entry")
+	}
+}
+
+func TestCache_InspectWithMaxMemoryUsage(t *testing.T) {
+	cache := NewCache().WithMaxMemoryUsage(Kilobyte)
+	value := &[]byte{}
+	cache.Set("key", value)
+	sizeWhenWritten := cache.MemoryUsage()
+	// Modifying the value in place does not change the size that counts toward the memory usage of the cache
+	*value = make([]byte, 100)
+	if info, _ := cache.Inspect("key"); info.SizeInBytes != sizeWhenWritten {
+		t.Errorf("expected size to be %d, got %d", sizeWhenWritten, info.SizeInBytes)
+	}
+	cache.RecalculateMemoryUsage()
+	if info, _ := cache.Inspect("key"); info.SizeInBytes != cache.MemoryUsage() || info.SizeInBytes <= sizeWhenWritten {
+		t.Errorf("expected size to have been recalculated, got %d", info.SizeInBytes)
+	}
+}
+
+func TestCache_NextEvictionCandidates(t *testing.T) {
+	cache := NewCache().WithEvictionPolicy(LeastRecentlyUsed).WithRule(Rule{Pattern: "pinned", NoEviction: true})
+	cache.Set("1", "v1")
+	cache.Set("pinned", "v")
+	cache.Set("2", "v2")
+	cache.Set("3", "v3")
+	cache.Get("1")
+	candidates := cache.NextEvictionCandidates(2)
+	if len(candidates) != 2 {
+		t.Fatalf("expected 2 candidates, got %d", len(candidates))
+	}
+	if candidates[0].Key != "2" || candidates[0].PositionFromTail != 1 || candidates[1].Key != "3" || candidates[1].PositionFromTail != 2 {
+		t.Errorf("expected 2 and 3 to be the next eviction candidates, got %+v", candidates)
+	}
+	if candidates := cache.NextEvictionCandidates(10); len(candidates) != 3 || candidates[2].Key != "1" {
+		t.Errorf("expected every entry but pinned to be returned, got %+v", candidates)
+	}
+	if candidates := cache.NextEvictionCandidates(0); len(candidates) != 0 {
+		t.Errorf("expected no candidates, got %+v", candidates)
+	}
+	// NextEvictionCandidates must not have any side effect
+	cache.WithMaxSize(3)
+	cache.Set("4", "v4")
+	if _, exists := cache.Peek("2"); exists {
+		t.Error("expected 2 to have been evicted")
+	}
+}
+
+func TestCache_EvictionDistance(t *testing.T) {
+	cache := NewCache().WithRule(Rule{Pattern: "pinned", NoEviction: true})
+	cache.Set("1", "v1")
+	cache.Set("pinned", "v")
+	cache.Set("2", "v2")
+	if distance, ok := cache.EvictionDistance("1"); !ok || distance != 0 {
+		t.Errorf("expected 1 to have an eviction distance of 0, got %d", distance)
+	}
+	if distance, ok := cache.EvictionDistance("2"); !ok || distance != 1 {
+		t.Errorf("expected 2 to have an eviction distance of 1, because pinned may not be evicted, got %d", distance)
+	}
+	if _, ok := cache.EvictionDistance("pinned"); ok {
+		t.Error("expected EvictionDistance to return false for an entry that may not be evicted")
+	}
+	if _, ok := cache.EvictionDistance("nope"); ok {
+		t.Error("expected EvictionDistance to return false for a key that doesn't exist")
+	}
+}
@@ -0,0 +1,138 @@
+package gocache
+
+import "iter"
+
+// iteratorPageSize is the maximum amount of entries that iterators retrieve every time they acquire the cache's lock
+const iteratorPageSize = 100
+
+// keyValue is a key along with the value of its entry
+type keyValue struct {
+	key   string
+	value any
+}
+
+// All returns an iterator over the keys and values of every entry of the cache, in no particular order.
+// Expired entries are skipped.
+//
+// Rather than copying every entry like GetAll, the iterator retrieves the entries in pages, and only holds the
+// cache's read lock while retrieving each page. This means that the body of the loop may use the cache, but also
+// that the iteration has the same consistency guarantees as Scan when the cache is modified during the iteration:
+//   - Every key present from the start to the end of the iteration is yielded exactly once
+//   - Keys created or deleted during the iteration may or may not be yielded
+//   - A key that is deleted and created again during the iteration may be yielded twice
+//   - The value yielded is the value the entry had when its page was retrieved
+//
+// Note that iterating does not count as accessing the entries, nor does it affect the statistics. The first iteration
+// enables the scan index if it isn't already enabled (see WithScanIndex).
+//
+//	for key, value := range cache.All() {
+//		fmt.Println(key, value)
+//	}
+func (cache *Cache) All() iter.Seq2[string, any] {
+	return func(yield func(string, any) bool) {
+		var cursor uint64
+		page := make([]keyValue, 0, iteratorPageSize)
+		for {
+			page = page[:0]
+			cache.rLockWithScanIndex()
+			entries, next := cache.scan(cursor, iteratorPageSize)
+			for _, entry := range entries {
+				if !entry.Expired() {
+					page = append(page, keyValue{key: entry.Key, value: entry.Value})
+				}
+			}
+			cache.mutex.RUnlock()
+			for _, kv := range page {
+				if !yield(kv.key, kv.value) {
+					return
+				}
+			}
+			if next == 0 {
+				return
+			}
+			cursor = next
+		}
+	}
+}
+
+// Keys returns an iterator over the keys of every entry of the cache, in no particular order.
+// Expired entries are skipped.
+//
+// See All for the consistency guarantees provided when the cache is modified during the iteration.
+func (cache *Cache) Keys() iter.Seq[string] {
+	return func(yield func(string) bool) {
+		for key := range cache.All() {
+			if !yield(key) {
+				return
+			}
+		}
+	}
+}
+
+// Values returns an iterator over the values of every entry of the cache, in no particular order.
+// Expired entries are skipped.
+//
+// See All for the consistency guarantees provided when the cache is modified during the iteration.
+func (cache *Cache) Values() iter.Seq[any] {
+	return func(yield func(any) bool) {
+		for _, value := range cache.All() {
+			if !yield(value) {
+				return
+			}
+		}
+	}
+}
+
+// EvictionOrder returns an iterator over the keys and values of every entry of the cache, in the order in which they
+// would be evicted (i.e. from the tail to the head). Expired entries are skipped.
+//
+// Like All, the iterator retrieves the entries in pages, and only holds the cache's read lock while retrieving each
+// page. When the cache is modified during the iteration, each page resumes from the position in the eviction order
+// where the previous page ended, which means that:
+//   - Every entry that stays in the cache without being moved during the iteration is yielded exactly once, in order
+//   - Entries created during the iteration are yielded, since they are put at the head
+//   - Entries moved to the head during the iteration (e.g. updated, or accessed if the eviction policy is
+//     LeastRecentlyUsed) may be yielded twice: once at their old position, and once at their new position
+//   - Entries deleted during the iteration may or may not be yielded
+//
+// Note that iterating does not count as accessing the entries, nor does it affect the statistics.
+func (cache *Cache) EvictionOrder() iter.Seq2[string, any] {
+	return func(yield func(string, any) bool) {
+		// last is the last entry of the previous page, and lastOrder is the order it had at the time
+		var last *Entry
+		var lastOrder uint64
+		page := make([]keyValue, 0, iteratorPageSize)
+		for {
+			page = page[:0]
+			cache.mutex.RLock()
+			current := cache.tail
+			if last != nil {
+				if cache.entries[last.Key] == last && last.order == lastOrder {
+					current = last.previous
+				} else {
+					// The last entry of the previous page was removed or moved, but because entries are sorted by
+					// order from the tail to the head, the page can resume from the first entry with a greater order
+					for current != nil && current.order <= lastOrder {
+						current = current.previous
+					}
+				}
+			}
+			for ; current != nil && len(page) < iteratorPageSize; current = current.previous {
+				last, lastOrder = current, current.order
+				if !current.Expired() {
+					page = append(page, keyValue{key: current.Key, value: current.Value})
+				}
+			}
+			done := current == nil
+			cache.mutex.RUnlock()
+			for _, kv := range page {
+				if !yield(kv.key, kv.value) {
+					return
+				}
+			}
+			if done {
+				return
+			}
+		}
+	}
+}
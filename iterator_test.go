@@ -0,0 +1,144 @@
+package gocache
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestCache_All(t *testing.T) {
+	cache := NewCache().WithMaxSize(NoMaxSize)
+	for i := 0; i < 250; i++ {
+		cache.Set(strconv.Itoa(i), i)
+	}
+	cache.SetWithTTL("expired", "value", time.Nanosecond)
+	time.Sleep(time.Millisecond)
+	entries := make(map[string]any)
+	for key, value := range cache.All() {
+		if _, exists := entries[key]; exists {
+			t.Errorf("expected %s to only be yielded once", key)
+		}
+		entries[key] = value
+	}
+	if len(entries) != 250 {
+		t.Errorf("expected 250 entries, got %d", len(entries))
+	}
+	if entries["42"] != 42 {
+		t.Errorf("expected %d, got %v", 42, entries["42"])
+	}
+	if _, exists := entries["expired"]; exists {
+		t.Error("expected expired entries to be skipped")
+	}
+	if stats := cache.Stats(); stats.Hits != 0 || stats.Misses != 0 {
+		t.Errorf("expected iterating not to affect the statistics, got %+v", stats)
+	}
+}
+
+func TestCache_AllWithBreak(t *testing.T) {
+	cache := NewCache()
+	for i := 0; i < 250; i++ {
+		cache.Set(strconv.Itoa(i), i)
+	}
+	n := 0
+	for range cache.All() {
+		n++
+		if n == 150 {
+			break
+		}
+	}
+	if n != 150 {
+		t.Errorf("expected iteration to stop after 150 entries, got %d", n)
+	}
+}
+
+func TestCache_AllWithConcurrentModifications(t *testing.T) {
+	cache := NewCache().WithMaxSize(NoMaxSize)
+	for i := 0; i < 500; i++ {
+		cache.Set(fmt.Sprintf("stable:%d", i), i)
+		cache.Set(fmt.Sprintf("volatile:%d", i), i)
+	}
+	seen := make(map[string]int)
+	i := 0
+	for key := range cache.Keys() {
+		seen[key]++
+		// The body of the loop may use the cache, since the lock is not held while yielding
+		if i < 500 {
+			// Keys created during the iteration may be yielded, so creating one for every key yielded would never end
+			cache.Delete(fmt.Sprintf("volatile:%d", i))
+			cache.Set(fmt.Sprintf("new:%d", i), i)
+			cache.Set(fmt.Sprintf("stable:%d", i), "updated")
+		}
+		i++
+	}
+	for i := 0; i < 500; i++ {
+		if count := seen[fmt.Sprintf("stable:%d", i)]; count != 1 {
+			t.Errorf("expected stable:%d to have been yielded exactly once, got %d", i, count)
+		}
+	}
+}
+
+func TestCache_KeysAndValues(t *testing.T) {
+	cache := NewCache()
+	cache.Set("a", 1)
+	cache.Set("b", 2)
+	keys := slices.Sorted(cache.Keys())
+	if !slices.Equal(keys, []string{"a", "b"}) {
+		t.Errorf("expected [a b], got %v", keys)
+	}
+	var sum int
+	for value := range cache.Values() {
+		sum += value.(int)
+	}
+	if sum != 3 {
+		t.Errorf("expected sum of values to be 3, got %d", sum)
+	}
+}
+
+func TestCache_EvictionOrder(t *testing.T) {
+	cache := NewCache().WithMaxSize(NoMaxSize).WithEvictionPolicy(LeastRecentlyUsed)
+	for i := 0; i < 250; i++ {
+		cache.Set(strconv.Itoa(i), i)
+	}
+	cache.Get("0")
+	var keys []string
+	for key := range cache.EvictionOrder() {
+		keys = append(keys, key)
+	}
+	if len(keys) != 250 {
+		t.Fatalf("expected 250 keys, got %d", len(keys))
+	}
+	if keys[0] != "1" || keys[248] != "249" || keys[249] != "0" {
+		t.Errorf("expected keys to be in eviction order, got %v", keys)
+	}
+}
+
+func TestCache_EvictionOrderWithConcurrentModifications(t *testing.T) {
+	cache := NewCache().WithMaxSize(NoMaxSize).WithEvictionPolicy(LeastRecentlyUsed)
+	for i := 0; i < 1000; i++ {
+		cache.Set(strconv.Itoa(i), i)
+	}
+	var keys []string
+	for key := range cache.EvictionOrder() {
+		keys = append(keys, key)
+		switch len(keys) {
+		case 100:
+			// Remove the last entry of the first page, which forces the next page to resume using the order
+			cache.Delete(key)
+		case 200:
+			// Move the last entry of the second page to the head
+			cache.Get(key)
+		case 300:
+			cache.Set("new", "value")
+		}
+	}
+	expectedKeys := make([]string, 0, 1002)
+	for i := 0; i < 1000; i++ {
+		expectedKeys = append(expectedKeys, strconv.Itoa(i))
+	}
+	expectedKeys = append(expectedKeys, "199", "new")
+	if !slices.Equal(keys, expectedKeys) {
+		t.Errorf("expected %v, got %v", expectedKeys, keys)
+	}
+}
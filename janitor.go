@@ -75,7 +75,7 @@ func (cache *Cache) StartJanitor() error {
 							// Because delete will remove the previous reference from the entry, we need to store the
 							// previous reference before we delete it
 							previous = current.previous
-							cache.delete(current.Key)
+							cache.remove(current, Expired)
 							cache.stats.ExpiredKeys++
 						}
 						if current == cache.head {
@@ -113,7 +113,7 @@ func (cache *Cache) StartJanitor() error {
 						backOff = JanitorMaxShiftBackOff
 					}
 				}
-				cache.mutex.Unlock()
+				cache.unlock()
 			case <-cache.stopJanitor:
 				cache.stopJanitor <- true
 				return
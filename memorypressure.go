@@ -0,0 +1,202 @@
+package gocache
+
+import (
+	"errors"
+	"math"
+	"runtime/metrics"
+	"time"
+)
+
+const (
+	// DefaultMemoryPressureInterval is the interval at which the memory pressure monitor reads the runtime metrics
+	// when MemoryPressureConfig.Interval is not set
+	DefaultMemoryPressureInterval = time.Second
+
+	// DefaultGoMemLimitRatio is the fraction of GOMEMLIMIT used as target when neither
+	// MemoryPressureConfig.TargetHeapSize nor MemoryPressureConfig.GoMemLimitRatio are set
+	DefaultGoMemLimitRatio = 0.8
+)
+
+var (
+	ErrMemoryPressureMonitorAlreadyRunning = errors.New("memory pressure monitor is already running")                        // Returned when the memory pressure monitor has already been started
+	ErrNoMemoryPressureTarget              = errors.New("memory pressure monitor requires a target heap size or GOMEMLIMIT") // Returned when the memory pressure monitor has no target
+)
+
+// MemoryLimitChangeReason is the reason why the memory pressure monitor last changed the cache's effective
+// MaxMemoryUsage
+type MemoryLimitChangeReason string
+
+const (
+	// HeapAboveTarget means that the effective MaxMemoryUsage was lowered because the live heap exceeded the target
+	HeapAboveTarget MemoryLimitChangeReason = "heap-above-target"
+
+	// HeapBelowTarget means that the effective MaxMemoryUsage was raised because the live heap was below the target
+	HeapBelowTarget MemoryLimitChangeReason = "heap-below-target"
+)
+
+// MemoryPressureConfig is the configuration of the memory pressure monitor started through
+// Cache.StartMemoryPressureMonitor
+type MemoryPressureConfig struct {
+	// TargetHeapSize is the size in bytes that the live heap of the entire process should stay under.
+	//
+	// Defaults to 0, which means that the target is GoMemLimitRatio of GOMEMLIMIT.
+	TargetHeapSize int
+
+	// GoMemLimitRatio is the fraction of GOMEMLIMIT used as target when TargetHeapSize is not set.
+	//
+	// Defaults to DefaultGoMemLimitRatio.
+	GoMemLimitRatio float64
+
+	// MinMemoryUsage is the value below which the effective MaxMemoryUsage is never lowered.
+	//
+	// Defaults to 0, which means that the cache may be shrunk until it's almost empty.
+	MinMemoryUsage int
+
+	// MaxMemoryUsage is the value above which the effective MaxMemoryUsage is never raised.
+	//
+	// Defaults to 0, which means that the cache's MaxMemoryUsage is used if it has one, and the target otherwise.
+	MaxMemoryUsage int
+
+	// Interval is the interval at which the runtime metrics are read.
+	//
+	// Defaults to DefaultMemoryPressureInterval.
+	Interval time.Duration
+}
+
+// memoryPressureMonitor is the state of the memory pressure monitor
+type memoryPressureMonitor struct {
+	// originalMaxMemoryUsage is the cache's MaxMemoryUsage when the monitor was started, which is restored when the
+	// monitor is stopped
+	originalMaxMemoryUsage int
+
+	// stop is the channel used to stop the monitor
+	stop chan bool
+}
+
+// StartMemoryPressureMonitor starts a goroutine that periodically reads the size of the live heap from the runtime
+// metrics, and adjusts the cache's effective MaxMemoryUsage so that the process stays under a target:
+//   - If the live heap exceeds the target, the effective MaxMemoryUsage is lowered by the excess, and entries are
+//     evicted the same way they are when MaxMemoryUsage is exceeded
+//   - If the live heap is below the target, the effective MaxMemoryUsage is raised by half of the difference, up to
+//     MemoryPressureConfig.MaxMemoryUsage
+//
+// While the monitor is running, MaxMemoryUsage returns the effective MaxMemoryUsage, which is also available through
+// Stats along with the reason it last changed. The monitor can be stopped by calling Cache.StopMemoryPressureMonitor,
+// which restores the MaxMemoryUsage the cache had before the monitor was started.
+//
+// Note that the live heap is only measured at the end of each garbage collection cycle, which means that the
+// monitor reacts to memory pressure with a delay that depends on how often the garbage collector runs.
+//
+// Returns ErrNoMemoryPressureTarget if MemoryPressureConfig.TargetHeapSize is not set and GOMEMLIMIT is not set either.
+//
+//	err := cache.StartMemoryPressureMonitor(gocache.MemoryPressureConfig{TargetHeapSize: 512 * gocache.Megabyte})
+func (cache *Cache) StartMemoryPressureMonitor(config MemoryPressureConfig) error {
+	if config.TargetHeapSize <= 0 {
+		if config.GoMemLimitRatio <= 0 || config.GoMemLimitRatio > 1 {
+			config.GoMemLimitRatio = DefaultGoMemLimitRatio
+		}
+		_, goMemLimit := readHeapMetrics()
+		if goMemLimit <= 0 || goMemLimit == math.MaxInt64 {
+			return ErrNoMemoryPressureTarget
+		}
+		config.TargetHeapSize = int(float64(goMemLimit) * config.GoMemLimitRatio)
+	}
+	if config.Interval <= 0 {
+		config.Interval = DefaultMemoryPressureInterval
+	}
+	config.MinMemoryUsage = max(config.MinMemoryUsage, 1)
+	cache.mutex.Lock()
+	if cache.memoryPressureMonitor != nil {
+		cache.mutex.Unlock()
+		return ErrMemoryPressureMonitorAlreadyRunning
+	}
+	if config.MaxMemoryUsage <= 0 {
+		config.MaxMemoryUsage = cache.maxMemoryUsage
+		if config.MaxMemoryUsage == NoMaxMemoryUsage {
+			config.MaxMemoryUsage = config.TargetHeapSize
+		}
+	}
+	config.MaxMemoryUsage = max(config.MaxMemoryUsage, config.MinMemoryUsage)
+	monitor := &memoryPressureMonitor{originalMaxMemoryUsage: cache.maxMemoryUsage, stop: make(chan bool)}
+	cache.memoryPressureMonitor = monitor
+	if cache.maxMemoryUsage == NoMaxMemoryUsage || cache.maxMemoryUsage > config.MaxMemoryUsage {
+		cache.maxMemoryUsage = config.MaxMemoryUsage
+		// The size of the existing entries may not have been tracked until now
+		cache.recalculateMemoryUsage()
+		cache.evictIfNecessary()
+	}
+	cache.unlock()
+	go func() {
+		for {
+			select {
+			case <-time.After(config.Interval):
+				heapLive, _ := readHeapMetrics()
+				cache.adjustMaxMemoryUsage(heapLive, config)
+			case <-monitor.stop:
+				monitor.stop <- true
+				return
+			}
+		}
+	}()
+	return nil
+}
+
+// StopMemoryPressureMonitor stops the memory pressure monitor, and restores the MaxMemoryUsage the cache had before
+// the monitor was started
+func (cache *Cache) StopMemoryPressureMonitor() {
+	cache.mutex.Lock()
+	monitor := cache.memoryPressureMonitor
+	cache.mutex.Unlock()
+	if monitor == nil {
+		return
+	}
+	// Tell the monitor to stop, and then wait for the monitor to reply on the same channel that it's stopping, which
+	// guarantees that it won't change the MaxMemoryUsage after it's restored
+	monitor.stop <- true
+	<-monitor.stop
+	cache.mutex.Lock()
+	cache.memoryPressureMonitor = nil
+	cache.maxMemoryUsage = monitor.originalMaxMemoryUsage
+	cache.memoryLimitChangeReason = ""
+	if len(cache.entries) > 0 {
+		cache.recalculateMemoryUsage()
+	}
+	cache.evictIfNecessary()
+	cache.unlock()
+}
+
+// adjustMaxMemoryUsage lowers or raises the effective MaxMemoryUsage based on the size of the live heap, and evicts
+// entries if the cache ends up exceeding it
+func (cache *Cache) adjustMaxMemoryUsage(heapLive int, config MemoryPressureConfig) {
+	cache.mutex.Lock()
+	defer cache.unlock()
+	if heapLive > config.TargetHeapSize {
+		// The cache is part of the heap, so shrinking the cache by the excess should bring the heap back to the target
+		maxMemoryUsage := max(cache.memoryUsage-(heapLive-config.TargetHeapSize), config.MinMemoryUsage)
+		if maxMemoryUsage < cache.maxMemoryUsage {
+			cache.maxMemoryUsage = maxMemoryUsage
+			cache.memoryLimitChangeReason = HeapAboveTarget
+			cache.evictIfNecessary()
+		}
+	} else if heapLive < config.TargetHeapSize && cache.maxMemoryUsage < config.MaxMemoryUsage {
+		// Only use half of the headroom, as the rest of the process may need it as well
+		maxMemoryUsage := min(cache.maxMemoryUsage+(config.TargetHeapSize-heapLive)/2, config.MaxMemoryUsage)
+		if maxMemoryUsage > cache.maxMemoryUsage {
+			cache.maxMemoryUsage = maxMemoryUsage
+			cache.memoryLimitChangeReason = HeapBelowTarget
+		}
+	}
+}
+
+// readHeapMetrics returns the size of the live heap as of the last garbage collection, and the value of GOMEMLIMIT
+func readHeapMetrics() (heapLive, goMemLimit int) {
+	samples := []metrics.Sample{{Name: "/gc/heap/live:bytes"}, {Name: "/gc/gomemlimit:bytes"}}
+	metrics.Read(samples)
+	if samples[0].Value.Kind() == metrics.KindUint64 {
+		heapLive = int(min(samples[0].Value.Uint64(), math.MaxInt64))
+	}
+	if samples[1].Value.Kind() == metrics.KindUint64 {
+		goMemLimit = int(min(samples[1].Value.Uint64(), math.MaxInt64))
+	}
+	return heapLive, goMemLimit
+}
@@ -0,0 +1,84 @@
+package gocache
+
+import (
+	"errors"
+	"math"
+	"runtime"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestCache_adjustMaxMemoryUsage(t *testing.T) {
+	cache := NewCache().WithMaxSize(NoMaxSize).WithMaxMemoryUsage(100 * Kilobyte)
+	for i := 0; i < 100; i++ {
+		cache.Set(strconv.Itoa(i), make([]byte, 500))
+	}
+	memoryUsage := cache.MemoryUsage()
+	config := MemoryPressureConfig{TargetHeapSize: 10 * Megabyte, MinMemoryUsage: Kilobyte, MaxMemoryUsage: 100 * Kilobyte}
+	// The heap exceeds the target by 10KB, so the cache must shrink by 10KB
+	cache.adjustMaxMemoryUsage(config.TargetHeapSize+10*Kilobyte, config)
+	stats := cache.Stats()
+	if stats.EffectiveMaxMemoryUsage != memoryUsage-10*Kilobyte || stats.MemoryLimitChangeReason != HeapAboveTarget {
+		t.Errorf("expected effective max memory usage to be %d because %s, got %d because %s", memoryUsage-10*Kilobyte, HeapAboveTarget, stats.EffectiveMaxMemoryUsage, stats.MemoryLimitChangeReason)
+	}
+	if cache.MemoryUsage() > stats.EffectiveMaxMemoryUsage || stats.EvictedKeys == 0 {
+		t.Errorf("expected entries to have been evicted, got a memory usage of %d", cache.MemoryUsage())
+	}
+	// The heap exceeds the target by far more than the size of the cache, so the cache must shrink to the minimum
+	cache.adjustMaxMemoryUsage(config.TargetHeapSize*2, config)
+	if cache.MaxMemoryUsage() != config.MinMemoryUsage {
+		t.Errorf("expected effective max memory usage to be %d, got %d", config.MinMemoryUsage, cache.MaxMemoryUsage())
+	}
+	// The heap is 20KB below the target, so the cache may grow by half of that
+	cache.adjustMaxMemoryUsage(config.TargetHeapSize-20*Kilobyte, config)
+	if stats := cache.Stats(); stats.EffectiveMaxMemoryUsage != config.MinMemoryUsage+10*Kilobyte || stats.MemoryLimitChangeReason != HeapBelowTarget {
+		t.Errorf("expected effective max memory usage to be %d because %s, got %d because %s", config.MinMemoryUsage+10*Kilobyte, HeapBelowTarget, stats.EffectiveMaxMemoryUsage, stats.MemoryLimitChangeReason)
+	}
+	// The cache must never grow above the maximum
+	cache.adjustMaxMemoryUsage(0, config)
+	if cache.MaxMemoryUsage() != config.MaxMemoryUsage {
+		t.Errorf("expected effective max memory usage to be %d, got %d", config.MaxMemoryUsage, cache.MaxMemoryUsage())
+	}
+}
+
+func TestCache_StartMemoryPressureMonitor(t *testing.T) {
+	cache := NewCache()
+	cache.Set("key", "value")
+	// The live heap is only measured at the end of each garbage collection cycle
+	runtime.GC()
+	// The live heap of the tests is far above 1 byte, so the cache should be shrunk to the minimum
+	err := cache.StartMemoryPressureMonitor(MemoryPressureConfig{TargetHeapSize: 1, MaxMemoryUsage: Megabyte, Interval: time.Millisecond})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := cache.StartMemoryPressureMonitor(MemoryPressureConfig{TargetHeapSize: 1}); !errors.Is(err, ErrMemoryPressureMonitorAlreadyRunning) {
+		t.Errorf("expected %v, got %v", ErrMemoryPressureMonitorAlreadyRunning, err)
+	}
+	if cache.MemoryUsage() == 0 {
+		t.Error("expected the size of the existing entries to have been computed when the monitor was started")
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for cache.Count() != 0 && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	if cache.Count() != 0 || cache.Stats().MemoryLimitChangeReason != HeapAboveTarget {
+		t.Errorf("expected the entry to have been evicted because the heap is above the target, got %d entries", cache.Count())
+	}
+	cache.StopMemoryPressureMonitor()
+	if cache.MaxMemoryUsage() != NoMaxMemoryUsage || cache.Stats().MemoryLimitChangeReason != "" {
+		t.Errorf("expected the max memory usage to have been restored, got %d", cache.MaxMemoryUsage())
+	}
+	// Check if stopping the monitor even though it's already stopped causes a panic
+	cache.StopMemoryPressureMonitor()
+}
+
+func TestCache_StartMemoryPressureMonitorWithoutTarget(t *testing.T) {
+	if _, goMemLimit := readHeapMetrics(); goMemLimit != math.MaxInt64 {
+		t.Skip("GOMEMLIMIT is set")
+	}
+	cache := NewCache()
+	if err := cache.StartMemoryPressureMonitor(MemoryPressureConfig{}); !errors.Is(err, ErrNoMemoryPressureTarget) {
+		t.Errorf("expected %v, got %v", ErrNoMemoryPressureTarget, err)
+	}
+}
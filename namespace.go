@@ -0,0 +1,491 @@
+package gocache
+
+import (
+	"context"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// NamespaceSeparator is the separator between the name of a Namespace and the keys of its entries in the parent Cache
+const NamespaceSeparator = ":"
+
+// Namespace is a view of a Cache that is scoped to the keys prefixed by the name of the namespace followed by
+// NamespaceSeparator. In other words, calling Set("1", value) on the namespace "users" is equivalent to calling
+// Set("users:1", value) on the parent Cache.
+//
+// A Namespace shares the MaxSize, the MaxMemoryUsage and the eviction order of its parent Cache, but has its own
+// default TTL and statistics, and can be counted and cleared without having to go through every entry of the
+// parent Cache.
+//
+// Configuration and features that apply to the entire cache (e.g. Subscribe, ChangesSince and the janitor) are
+// only available through the parent Cache.
+//
+// Do not instantiate this struct directly, use Cache.Namespace instead
+type Namespace struct {
+	cache *Cache
+
+	// name is the name of the namespace
+	name string
+
+	// prefix is the prefix of the keys of the namespace in the parent cache
+	prefix string
+
+	// defaultTTL is the default TTL for each entry of the namespace
+	// Defaults to the same default TTL as Cache.Set
+	defaultTTL time.Duration
+
+	// entries are the entries of the parent cache that are part of the namespace, indexed by their key in the
+	// parent cache
+	//
+	// Like every other field below, this is protected by the parent cache's lock.
+	entries map[string]*Entry
+
+	// stats is the object that contains the namespace's statistics/metrics
+	stats Statistics
+}
+
+// Namespace returns the Namespace with the name passed as parameter, creating it if it doesn't exist yet.
+//
+// Entries of the parent Cache whose key is prefixed by the name of the namespace followed by NamespaceSeparator are
+// part of the namespace, including entries created before the namespace itself. If namespaces are nested (e.g.
+// "users" and "users:admins"), entries are only part of the most specific namespace.
+//
+//	users := cache.Namespace("users").WithDefaultTTL(time.Hour)
+//	users.Set("1", user) // equivalent to cache.SetWithTTL("users:1", user, time.Hour)
+//	users.Count()        // number of entries in the "users" namespace
+func (cache *Cache) Namespace(name string) *Namespace {
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+	if ns, ok := cache.namespaces[name]; ok {
+		return ns
+	}
+	ns := &Namespace{
+		cache:      cache,
+		name:       name,
+		prefix:     name + NamespaceSeparator,
+		defaultTTL: useDefaultTTL,
+		entries:    make(map[string]*Entry),
+	}
+	if cache.namespaces == nil {
+		cache.namespaces = make(map[string]*Namespace)
+	}
+	cache.namespaces[name] = ns
+	// Adopt the existing entries that are part of the new namespace
+	for key, entry := range cache.entries {
+		if current := entry.namespace(); strings.HasPrefix(key, ns.prefix) && (current == nil || len(ns.prefix) > len(current.prefix)) {
+			if current != nil {
+				delete(current.entries, key)
+			}
+			entry.meta().namespace = ns
+			ns.entries[key] = entry
+		}
+	}
+	return ns
+}
+
+// addToNamespace adds a newly created entry to the namespace it is part of, if any
+//
+// Must be called while the cache's lock is held.
+func (cache *Cache) addToNamespace(entry *Entry) {
+	var namespace *Namespace
+	for _, ns := range cache.namespaces {
+		if strings.HasPrefix(entry.Key, ns.prefix) && (namespace == nil || len(ns.prefix) > len(namespace.prefix)) {
+			namespace = ns
+		}
+	}
+	if namespace != nil {
+		entry.meta().namespace = namespace
+		namespace.entries[entry.Key] = entry
+	}
+}
+
+// removeFromNamespace removes an entry from the namespace it is part of, if any
+//
+// Must be called while the cache's lock is held.
+func (cache *Cache) removeFromNamespace(entry *Entry, reason RemovalReason) {
+	namespace := entry.namespace()
+	if namespace == nil {
+		return
+	}
+	delete(namespace.entries, entry.Key)
+	switch reason {
+	case Evicted:
+		namespace.stats.EvictedKeys++
+	case Expired:
+		namespace.stats.ExpiredKeys++
+	}
+	entry.metadata.namespace = nil
+}
+
+// Name returns the name of the namespace
+func (ns *Namespace) Name() string {
+	return ns.name
+}
+
+// WithDefaultTTL sets the default TTL for each entry of the namespace, which takes precedence over the rules of the
+// parent cache (see Cache.WithRule)
+//
+// Defaults to the same default TTL as Cache.Set
+func (ns *Namespace) WithDefaultTTL(ttl time.Duration) *Namespace {
+	if ttl > 1 {
+		ns.defaultTTL = ttl
+	}
+	return ns
+}
+
+// Stats returns statistics from the namespace
+//
+// Hits and Misses are only recorded by Get, GetValue, GetByKeys, GetAll and GetWithVersion.
+func (ns *Namespace) Stats() Statistics {
+	ns.cache.mutex.RLock()
+	stats := ns.stats
+	ns.cache.mutex.RUnlock()
+	return stats
+}
+
+// Set creates or updates a key with a given value using the namespace's default TTL
+func (ns *Namespace) Set(key string, value any) {
+	ns.cache.SetWithTTL(ns.prefix+key, value, ns.defaultTTL)
+}
+
+// SetWithTTL creates or updates a key with a given value and sets an expiration time (-1 is NoExpiration)
+//
+// See Cache.SetWithTTL
+func (ns *Namespace) SetWithTTL(key string, value any, ttl time.Duration) {
+	ns.cache.SetWithTTL(ns.prefix+key, value, ttl)
+}
+
+// SetAll creates or updates multiple values using the namespace's default TTL
+func (ns *Namespace) SetAll(entries map[string]any) {
+	ns.SetAllWithTTL(entries, ns.defaultTTL)
+}
+
+// SetAllWithTTL creates or updates multiple values
+func (ns *Namespace) SetAllWithTTL(entries map[string]any, ttl time.Duration) {
+	for key, value := range entries {
+		ns.SetWithTTL(key, value, ttl)
+	}
+}
+
+// SetWithTags creates or updates a key with a given value and expiration time, and associates the entry with the
+// tags passed as parameter
+//
+// See Cache.SetWithTags
+func (ns *Namespace) SetWithTags(key string, value any, ttl time.Duration, tags ...string) {
+	ns.cache.SetWithTags(ns.prefix+key, value, ttl, tags...)
+}
+
+// SetIfAbsent creates a key with a given value if the key does not exist, using the namespace's default TTL
+//
+// See Cache.SetIfAbsentWithTTL
+func (ns *Namespace) SetIfAbsent(key string, value any) bool {
+	return ns.cache.SetIfAbsentWithTTL(ns.prefix+key, value, ns.defaultTTL)
+}
+
+// SetIfAbsentWithTTL creates a key with a given value and expiration time if the key does not exist
+//
+// See Cache.SetIfAbsentWithTTL
+func (ns *Namespace) SetIfAbsentWithTTL(key string, value any, ttl time.Duration) bool {
+	return ns.cache.SetIfAbsentWithTTL(ns.prefix+key, value, ttl)
+}
+
+// SetIfPresent updates the value of a key if the key exists, using the namespace's default TTL
+//
+// See Cache.SetIfPresentWithTTL
+func (ns *Namespace) SetIfPresent(key string, value any) bool {
+	return ns.cache.SetIfPresentWithTTL(ns.prefix+key, value, ns.defaultTTL)
+}
+
+// SetIfPresentWithTTL updates the value and the expiration time of a key if the key exists
+//
+// See Cache.SetIfPresentWithTTL
+func (ns *Namespace) SetIfPresentWithTTL(key string, value any, ttl time.Duration) bool {
+	return ns.cache.SetIfPresentWithTTL(ns.prefix+key, value, ttl)
+}
+
+// CompareAndSwap updates the value of a key to newValue if and only if its current value is equal to oldValue
+//
+// See Cache.CompareAndSwap
+func (ns *Namespace) CompareAndSwap(key string, oldValue, newValue any) bool {
+	return ns.cache.compareAndSwap(ns.prefix+key, oldValue, newValue, ns.defaultTTL)
+}
+
+// GetAndSet creates or updates a key with a given value using the namespace's default TTL, and returns the value
+// that the key had before the update
+//
+// See Cache.GetAndSet
+func (ns *Namespace) GetAndSet(key string, value any) (any, bool) {
+	return ns.cache.getAndSet(ns.prefix+key, value, ns.defaultTTL)
+}
+
+// GetAndDelete removes a key from the namespace and returns the value it had
+//
+// See Cache.GetAndDelete
+func (ns *Namespace) GetAndDelete(key string) (any, bool) {
+	return ns.cache.GetAndDelete(ns.prefix + key)
+}
+
+// Update atomically updates the value of a key using the function passed as parameter
+//
+// See Cache.Update
+func (ns *Namespace) Update(key string, fn func(old any, exists bool) (newValue any, ttl time.Duration, keep bool)) (any, bool) {
+	return ns.cache.Update(ns.prefix+key, fn)
+}
+
+// Increment increments the value of a key by delta and returns the new value, creating the key with the
+// namespace's default TTL if it doesn't exist
+//
+// See Cache.Increment
+func (ns *Namespace) Increment(key string, delta int64) (int64, error) {
+	return ns.cache.increment(ns.prefix+key, delta, ns.defaultTTL)
+}
+
+// Decrement decrements the value of a key by delta and returns the new value, creating the key with the
+// namespace's default TTL if it doesn't exist
+//
+// See Cache.Decrement
+func (ns *Namespace) Decrement(key string, delta int64) (int64, error) {
+	return ns.cache.decrement(ns.prefix+key, delta, ns.defaultTTL)
+}
+
+// IncrementFloat increments the value of a key by delta and returns the new value, creating the key with the
+// namespace's default TTL if it doesn't exist
+//
+// See Cache.IncrementFloat
+func (ns *Namespace) IncrementFloat(key string, delta float64) (float64, error) {
+	return ns.cache.incrementFloat(ns.prefix+key, delta, ns.defaultTTL)
+}
+
+// SetIfVersion creates or updates a key only if the entry's current version is equal to expectedVersion
+//
+// See Cache.SetIfVersion
+func (ns *Namespace) SetIfVersion(key string, value any, ttl time.Duration, expectedVersion uint64) error {
+	return ns.cache.SetIfVersion(ns.prefix+key, value, ttl, expectedVersion)
+}
+
+// Transaction executes the function passed as parameter with a Tx scoped to the namespace, and commits every write
+// made through the Tx atomically if the function returns nil
+//
+// See Cache.Transaction
+func (ns *Namespace) Transaction(fn func(tx *Tx) error) error {
+	return ns.cache.transaction(ns.prefix, ns.defaultTTL, fn)
+}
+
+// Get retrieves an entry using the key passed as parameter
+//
+// See Cache.Get
+func (ns *Namespace) Get(key string) (any, bool) {
+	ns.cache.mutex.Lock()
+	entry, ok := ns.access(ns.prefix + key)
+	if !ok {
+		ns.cache.unlock()
+		return nil, false
+	}
+	value := entry.Value
+	ns.cache.unlock()
+	return value, true
+}
+
+// GetValue retrieves an entry using the key passed as parameter
+// Unlike Get, this function only returns the value
+func (ns *Namespace) GetValue(key string) any {
+	value, _ := ns.Get(key)
+	return value
+}
+
+// GetByKeys retrieves multiple entries using the keys passed as parameter
+//
+// See Cache.GetByKeys
+func (ns *Namespace) GetByKeys(keys []string) map[string]any {
+	entries := make(map[string]any)
+	for _, key := range keys {
+		entries[key], _ = ns.Get(key)
+	}
+	return entries
+}
+
+// GetWithVersion retrieves an entry using the key passed as parameter, along with the entry's current version
+//
+// See Cache.GetWithVersion
+func (ns *Namespace) GetWithVersion(key string) (any, uint64, bool) {
+	ns.cache.mutex.Lock()
+	entry, ok := ns.access(ns.prefix + key)
+	if !ok {
+		ns.cache.unlock()
+		return nil, 0, false
+	}
+	value, version := entry.Value, entry.Version
+	ns.cache.unlock()
+	return value, version, true
+}
+
+// Peek retrieves the value of an entry using the key passed as parameter without any side effect
+//
+// See Cache.Peek
+func (ns *Namespace) Peek(key string) (any, bool) {
+	return ns.cache.Peek(ns.prefix + key)
+}
+
+// Inspect retrieves information about an entry using the key passed as parameter without any side effect
+//
+// Unlike Cache.Inspect, EntryInfo.Key does not include the namespace's prefix. See Cache.Inspect
+func (ns *Namespace) Inspect(key string) (EntryInfo, bool) {
+	info, ok := ns.cache.Inspect(ns.prefix + key)
+	if ok {
+		info.Key = key
+	}
+	return info, ok
+}
+
+// GetAll retrieves all entries of the namespace
+//
+// Unlike Cache.GetAll, this only goes through the entries of the namespace.
+func (ns *Namespace) GetAll() map[string]any {
+	ns.cache.mutex.Lock()
+	entries := make(map[string]any, len(ns.entries))
+	for key, entry := range ns.entries {
+		if entry.Expired() {
+			ns.cache.remove(entry, Expired)
+			continue
+		}
+		entries[strings.TrimPrefix(key, ns.prefix)] = entry.Value
+	}
+	ns.stats.Hits += uint64(len(entries))
+	ns.cache.stats.Hits += uint64(len(entries))
+	ns.cache.unlock()
+	return entries
+}
+
+// GetKeysByPattern retrieves a slice of keys of the namespace that match a given pattern
+//
+// The pattern is matched against the keys without the namespace's prefix. See Cache.GetKeysByPattern
+func (ns *Namespace) GetKeysByPattern(pattern string, limit int) []string {
+	return ns.getKeysMatching(func(key string) bool {
+		return ns.cache.patternDialect.Match(pattern, key)
+	}, limit)
+}
+
+// GetKeysByRegexp retrieves a slice of keys of the namespace that match a given regular expression
+//
+// The regular expression is matched against the keys without the namespace's prefix. See Cache.GetKeysByRegexp
+func (ns *Namespace) GetKeysByRegexp(re *regexp.Regexp, limit int) []string {
+	return ns.getKeysMatching(re.MatchString, limit)
+}
+
+func (ns *Namespace) getKeysMatching(match func(key string) bool, limit int) []string {
+	var matchingKeys []string
+	ns.cache.mutex.Lock()
+	for key, entry := range ns.entries {
+		if entry.Expired() {
+			continue
+		}
+		key = strings.TrimPrefix(key, ns.prefix)
+		if match(key) {
+			matchingKeys = append(matchingKeys, key)
+			if limit > 0 && len(matchingKeys) >= limit {
+				break
+			}
+		}
+	}
+	ns.cache.mutex.Unlock()
+	return matchingKeys
+}
+
+// WaitFor retrieves the value of an entry using the key passed as parameter, and if there is no such entry, blocks
+// until the entry is created or until the context passed as parameter is done
+//
+// See Cache.WaitFor
+func (ns *Namespace) WaitFor(ctx context.Context, key string) (any, error) {
+	return ns.cache.WaitFor(ctx, ns.prefix+key)
+}
+
+// Delete removes a key from the namespace
+//
+// Returns false if the key did not exist.
+func (ns *Namespace) Delete(key string) bool {
+	return ns.cache.Delete(ns.prefix + key)
+}
+
+// DeleteAll deletes multiple entries based on the keys passed as parameter
+//
+// Returns the number of keys deleted
+func (ns *Namespace) DeleteAll(keys []string) int {
+	prefixedKeys := make([]string, len(keys))
+	for i, key := range keys {
+		prefixedKeys[i] = ns.prefix + key
+	}
+	return ns.cache.DeleteAll(prefixedKeys)
+}
+
+// DeleteKeysByPattern deletes all entries of the namespace matching a given key pattern and returns the number of
+// entries deleted.
+func (ns *Namespace) DeleteKeysByPattern(pattern string) int {
+	return ns.DeleteAll(ns.GetKeysByPattern(pattern, 0))
+}
+
+// DeleteKeysByRegexp deletes all entries of the namespace whose key matches a given regular expression and returns
+// the number of entries deleted.
+func (ns *Namespace) DeleteKeysByRegexp(re *regexp.Regexp) int {
+	return ns.DeleteAll(ns.GetKeysByRegexp(re, 0))
+}
+
+// InvalidateTag deletes every entry of the namespace associated with the tag passed as parameter, and returns the
+// number of entries deleted.
+func (ns *Namespace) InvalidateTag(tag string) int {
+	ns.cache.mutex.Lock()
+	numberOfKeysDeleted := 0
+	for key := range ns.cache.tags[tag] {
+		if entry := ns.entries[key]; entry != nil {
+			ns.cache.remove(entry, Deleted)
+			numberOfKeysDeleted++
+		}
+	}
+	ns.cache.unlock()
+	return numberOfKeysDeleted
+}
+
+// Count returns the total amount of entries in the namespace, regardless of whether they're expired or not
+func (ns *Namespace) Count() int {
+	ns.cache.mutex.RLock()
+	count := len(ns.entries)
+	ns.cache.mutex.RUnlock()
+	return count
+}
+
+// Clear deletes all entries of the namespace
+func (ns *Namespace) Clear() {
+	ns.cache.mutex.Lock()
+	for _, entry := range ns.entries {
+		ns.cache.remove(entry, Cleared)
+	}
+	ns.cache.unlock()
+}
+
+// TTL returns the time until the entry specified by the key passed as parameter will be deleted
+//
+// See Cache.TTL
+func (ns *Namespace) TTL(key string) (time.Duration, error) {
+	return ns.cache.TTL(ns.prefix + key)
+}
+
+// Expire sets a key's expiration time
+//
+// See Cache.Expire
+func (ns *Namespace) Expire(key string, ttl time.Duration) bool {
+	return ns.cache.Expire(ns.prefix+key, ttl)
+}
+
+// access is the equivalent of Cache.access for the namespace, which also updates the namespace's statistics
+//
+// Must be called while the cache's lock is held.
+func (ns *Namespace) access(key string) (*Entry, bool) {
+	_, existed := ns.cache.get(key)
+	entry, ok := ns.cache.access(key)
+	if ok {
+		ns.stats.Hits++
+	} else if !existed {
+		ns.stats.Misses++
+	}
+	return entry, ok
+}
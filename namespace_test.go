@@ -0,0 +1,177 @@
+package gocache
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCache_Namespace(t *testing.T) {
+	cache := NewCache()
+	users := cache.Namespace("users")
+	if users != cache.Namespace("users") {
+		t.Error("expected the same namespace to be returned for the same name")
+	}
+	if users.Name() != "users" {
+		t.Errorf("expected name to be %s, got %s", "users", users.Name())
+	}
+	users.Set("1", "john")
+	cache.Set("products:1", "apple")
+	if value := cache.GetValue("users:1"); value != "john" {
+		t.Errorf("expected key to be prefixed by the namespace in the parent cache, got %v", value)
+	}
+	if value, ok := users.Get("1"); !ok || value != "john" {
+		t.Errorf("expected %s, got %v", "john", value)
+	}
+	if _, ok := users.Get("products:1"); ok {
+		t.Error("expected namespace not to have access to keys outside of it")
+	}
+	if users.Count() != 1 {
+		t.Errorf("expected namespace to have 1 entry, got %d", users.Count())
+	}
+	if cache.Count() != 2 {
+		t.Errorf("expected parent cache to have 2 entries, got %d", cache.Count())
+	}
+	if stats := users.Stats(); stats.Hits != 1 || stats.Misses != 1 {
+		t.Errorf("expected 1 hit and 1 miss, got %+v", stats)
+	}
+}
+
+func TestCache_NamespaceAdoptsExistingEntries(t *testing.T) {
+	cache := NewCache()
+	cache.Set("users:1", "john")
+	cache.Set("users:admins:1", "jane")
+	cache.Set("users-archive:1", "bob")
+	users := cache.Namespace("users")
+	if users.Count() != 2 {
+		t.Errorf("expected namespace to have adopted 2 entries, got %d", users.Count())
+	}
+	admins := cache.Namespace("users:admins")
+	if users.Count() != 1 || admins.Count() != 1 {
+		t.Errorf("expected entries to only be part of the most specific namespace, got %d and %d", users.Count(), admins.Count())
+	}
+	admins.Set("2", "alice")
+	if users.Count() != 1 || admins.Count() != 2 {
+		t.Errorf("expected new entry to be part of the most specific namespace, got %d and %d", users.Count(), admins.Count())
+	}
+	if value := users.GetValue("admins:2"); value != "alice" {
+		t.Errorf("expected %s, got %v", "alice", value)
+	}
+}
+
+func TestCache_NamespaceSharesCapacity(t *testing.T) {
+	cache := NewCache().WithMaxSize(3)
+	users := cache.Namespace("users")
+	products := cache.Namespace("products")
+	users.Set("1", "john")
+	users.Set("2", "jane")
+	products.Set("1", "apple")
+	products.Set("2", "banana")
+	if cache.Count() != 3 {
+		t.Errorf("expected parent cache to have 3 entries, got %d", cache.Count())
+	}
+	if users.Count() != 1 || products.Count() != 2 {
+		t.Errorf("expected users to have 1 entry and products to have 2 entries, got %d and %d", users.Count(), products.Count())
+	}
+	if stats := users.Stats(); stats.EvictedKeys != 1 {
+		t.Errorf("expected users to have 1 evicted key, got %d", stats.EvictedKeys)
+	}
+	if stats := products.Stats(); stats.EvictedKeys != 0 {
+		t.Errorf("expected products to have no evicted keys, got %d", stats.EvictedKeys)
+	}
+}
+
+func TestNamespace_WithDefaultTTL(t *testing.T) {
+	cache := NewCache().WithDefaultTTL(time.Hour)
+	sessions := cache.Namespace("sessions").WithDefaultTTL(time.Minute)
+	sessions.Set("1", "token")
+	cache.Set("other", "value")
+	if ttl, _ := sessions.TTL("1"); ttl > time.Minute {
+		t.Errorf("expected TTL to be at most a minute, got %s", ttl)
+	}
+	if ttl, _ := cache.TTL("other"); ttl < 59*time.Minute {
+		t.Errorf("expected parent cache's default TTL to be unaffected, got %s", ttl)
+	}
+	if _, err := sessions.Increment("counter", 1); err != nil {
+		t.Fatal(err)
+	}
+	if ttl, _ := sessions.TTL("counter"); ttl > time.Minute {
+		t.Errorf("expected Increment to use the namespace's default TTL, got %s", ttl)
+	}
+}
+
+func TestNamespace_Clear(t *testing.T) {
+	cache := NewCache()
+	users := cache.Namespace("users")
+	users.SetAll(map[string]any{"1": "john", "2": "jane"})
+	cache.Set("products:1", "apple")
+	users.Clear()
+	if users.Count() != 0 {
+		t.Errorf("expected namespace to be empty, got %d", users.Count())
+	}
+	if cache.Count() != 1 {
+		t.Errorf("expected entries outside of the namespace to be left untouched, got %d", cache.Count())
+	}
+	users.Set("3", "bob")
+	cache.Clear()
+	if users.Count() != 0 {
+		t.Errorf("expected namespace to be empty after parent cache was cleared, got %d", users.Count())
+	}
+}
+
+func TestNamespace_GetAllAndGetKeysByPattern(t *testing.T) {
+	cache := NewCache()
+	users := cache.Namespace("users")
+	users.SetAll(map[string]any{"1": "john", "2": "jane", "admin": "alice"})
+	users.SetWithTTL("expired", "bob", time.Nanosecond)
+	cache.Set("products:1", "apple")
+	time.Sleep(time.Millisecond)
+	if all := users.GetAll(); len(all) != 3 || all["admin"] != "alice" {
+		t.Errorf("expected 3 unprefixed entries, got %v", all)
+	}
+	if stats := users.Stats(); stats.ExpiredKeys != 1 {
+		t.Errorf("expected 1 expired key, got %d", stats.ExpiredKeys)
+	}
+	if keys := users.GetKeysByPattern("*", 0); len(keys) != 3 {
+		t.Errorf("expected 3 keys, got %v", keys)
+	}
+	if numberOfKeysDeleted := users.DeleteKeysByPattern("?"); numberOfKeysDeleted != 2 {
+		t.Errorf("expected 2 keys to have been deleted, got %d", numberOfKeysDeleted)
+	}
+	if cache.Count() != 2 {
+		t.Errorf("expected 2 entries to be left, got %d", cache.Count())
+	}
+}
+
+func TestNamespace_InvalidateTag(t *testing.T) {
+	cache := NewCache()
+	users := cache.Namespace("users")
+	users.SetWithTags("1", "john", NoExpiration, "stale")
+	cache.SetWithTags("products:1", "apple", NoExpiration, "stale")
+	if numberOfKeysDeleted := users.InvalidateTag("stale"); numberOfKeysDeleted != 1 {
+		t.Errorf("expected 1 key to have been deleted, got %d", numberOfKeysDeleted)
+	}
+	if _, exists := cache.Get("products:1"); !exists {
+		t.Error("expected entries outside of the namespace to be left untouched")
+	}
+}
+
+func TestNamespace_Transaction(t *testing.T) {
+	cache := NewCache()
+	users := cache.Namespace("users")
+	users.Set("1", "john")
+	err := users.Transaction(func(tx *Tx) error {
+		value, _ := tx.Get("1")
+		tx.Set("2", value)
+		tx.Delete("1")
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if value := cache.GetValue("users:2"); value != "john" {
+		t.Errorf("expected %s, got %v", "john", value)
+	}
+	if users.Count() != 1 {
+		t.Errorf("expected namespace to have 1 entry, got %d", users.Count())
+	}
+}
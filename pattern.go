@@ -1,6 +1,32 @@
 package gocache
 
-import "path/filepath"
+import (
+	"path/filepath"
+	"strings"
+	"unicode/utf8"
+)
+
+// PatternDialect is the syntax and semantics of the patterns used to match keys (e.g. GetKeysByPattern)
+type PatternDialect string
+
+const (
+	// FilepathGlob is the dialect implemented by filepath.Match, in which '*' and '?' do not match the path
+	// separator ('/' on most systems). See MatchPattern.
+	FilepathGlob PatternDialect = "FilepathGlob"
+
+	// RedisGlob is the dialect used by Redis (e.g. KEYS, SCAN), in which '*' matches any sequence of characters and
+	// '?' matches any single character, including '/'. Character classes may be negated with '^' (e.g. "[^abc]"),
+	// and special characters may be escaped with '\'. See MatchRedisPattern.
+	RedisGlob PatternDialect = "RedisGlob"
+)
+
+// Match checks whether a string matches a pattern using the dialect
+func (dialect PatternDialect) Match(pattern, s string) bool {
+	if dialect == RedisGlob {
+		return MatchRedisPattern(pattern, s)
+	}
+	return MatchPattern(pattern, s)
+}
 
 // MatchPattern checks whether a string matches a pattern
 func MatchPattern(pattern, s string) bool {
@@ -10,3 +36,129 @@ func MatchPattern(pattern, s string) bool {
 	matched, _ := filepath.Match(pattern, s)
 	return matched
 }
+
+// MatchRedisPattern checks whether a string matches a pattern using the same semantics as Redis, which, unlike
+// MatchPattern, allows '*' and '?' to match '/'
+func MatchRedisPattern(pattern, s string) bool {
+	px, sx := 0, 0
+	// starPx and starSx are the positions in the pattern and in the string of the last '*', which are used to
+	// backtrack by making the '*' match one more character whenever the rest of the pattern doesn't match
+	starPx, starSx := -1, -1
+	for px < len(pattern) || sx < len(s) {
+		if px < len(pattern) {
+			if pattern[px] == '*' {
+				starPx, starSx = px, sx
+				px++
+				continue
+			}
+			if sx < len(s) {
+				r, width := utf8.DecodeRuneInString(s[sx:])
+				if length, matched := matchRedisPatternElement(pattern[px:], r); matched {
+					px += length
+					sx += width
+					continue
+				}
+			}
+		}
+		if starPx != -1 && starSx < len(s) {
+			_, width := utf8.DecodeRuneInString(s[starSx:])
+			starSx += width
+			px, sx = starPx+1, starSx
+			continue
+		}
+		return false
+	}
+	return true
+}
+
+// matchRedisPatternElement checks whether the first element of the pattern (a character, an escaped character, '?'
+// or a character class) matches a character, and returns the length of that element
+func matchRedisPatternElement(pattern string, r rune) (int, bool) {
+	switch pattern[0] {
+	case '?':
+		return 1, true
+	case '[':
+		if length, matched, ok := matchRedisCharacterClass(pattern, r); ok {
+			return length, matched
+		}
+	}
+	c, length := decodeEscapedRune(pattern, 0)
+	return length, c == r
+}
+
+// matchRedisCharacterClass checks whether the character class at the beginning of the pattern matches a character,
+// and returns the length of the character class
+//
+// Returns false as third value if the character class is not terminated, in which case '[' is a literal character.
+func matchRedisCharacterClass(pattern string, r rune) (int, bool, bool) {
+	i, negated, matched := 1, false, false
+	if i < len(pattern) && pattern[i] == '^' {
+		negated = true
+		i++
+	}
+	for i < len(pattern) && pattern[i] != ']' {
+		low, length := decodeEscapedRune(pattern, i)
+		i += length
+		high := low
+		if i+1 < len(pattern) && pattern[i] == '-' && pattern[i+1] != ']' {
+			high, length = decodeEscapedRune(pattern, i+1)
+			i += 1 + length
+			if low > high {
+				low, high = high, low
+			}
+		}
+		if low <= r && r <= high {
+			matched = true
+		}
+	}
+	if i >= len(pattern) {
+		return 0, false, false
+	}
+	return i + 1, matched != negated, true
+}
+
+// decodeEscapedRune decodes the character at the position i of the pattern, which may be escaped with '\', and
+// returns it along with its length in the pattern
+func decodeEscapedRune(pattern string, i int) (rune, int) {
+	if pattern[i] == '\\' && i+1 < len(pattern) {
+		r, length := utf8.DecodeRuneInString(pattern[i+1:])
+		return r, 1 + length
+	}
+	return utf8.DecodeRuneInString(pattern[i:])
+}
+
+// compilePattern returns a function that checks whether a string matches the pattern passed as parameter using the
+// dialect passed as parameter, which avoids going through the dialect's Match for the most common patterns
+// (e.g. "prefix:*")
+func compilePattern(dialect PatternDialect, pattern string) func(s string) bool {
+	if pattern == "*" {
+		return func(string) bool { return true }
+	}
+	prefix, hasWildcardSuffix := strings.CutSuffix(pattern, "*")
+	if !strings.ContainsAny(prefix, `*?[\`) {
+		if hasWildcardSuffix && dialect == RedisGlob {
+			return func(s string) bool { return strings.HasPrefix(s, prefix) }
+		}
+		if hasWildcardSuffix && !strings.Contains(prefix, string(filepath.Separator)) {
+			return func(s string) bool {
+				return strings.HasPrefix(s, prefix) && !strings.Contains(s[len(prefix):], string(filepath.Separator))
+			}
+		}
+		if !hasWildcardSuffix {
+			return func(s string) bool { return s == pattern }
+		}
+	}
+	return func(s string) bool {
+		return dialect.Match(pattern, s)
+	}
+}
+
+// literalPrefix returns the part of the pattern passed as parameter that precedes its first special character
+//
+// This is the same for every PatternDialect.
+func literalPrefix(pattern string) string {
+	if i := strings.IndexAny(pattern, `*?[\`); i != -1 {
+		return pattern[:i]
+	}
+	return pattern
+}
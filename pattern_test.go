@@ -119,3 +119,109 @@ func TestMatchPattern(t *testing.T) {
 		})
 	}
 }
+
+func TestMatchRedisPattern(t *testing.T) {
+	scenarios := []struct {
+		pattern                 string
+		key                     string
+		expectedToMatch         bool
+		expectedToMatchFilepath bool
+	}{
+		{pattern: "*", key: "users/1/profile", expectedToMatch: true, expectedToMatchFilepath: true},
+		{pattern: "users/*", key: "users/1/profile", expectedToMatch: true, expectedToMatchFilepath: false},
+		{pattern: "users/*/profile", key: "users/1/profile", expectedToMatch: true, expectedToMatchFilepath: true},
+		{pattern: "users/*/profile", key: "users/1/2/profile", expectedToMatch: true, expectedToMatchFilepath: false},
+		{pattern: "users?1", key: "users/1", expectedToMatch: true, expectedToMatchFilepath: false},
+		{pattern: "*profile", key: "users/1/profile", expectedToMatch: true, expectedToMatchFilepath: false},
+		{pattern: "**", key: "users/1/profile", expectedToMatch: true, expectedToMatchFilepath: false},
+		{pattern: "user:[0-9]", key: "user:5", expectedToMatch: true, expectedToMatchFilepath: true},
+		{pattern: "user:[^0-9]", key: "user:5", expectedToMatch: false, expectedToMatchFilepath: false},
+		{pattern: "user:[^0-9]", key: "user:a", expectedToMatch: true, expectedToMatchFilepath: true},
+		{pattern: "user:[9-0]", key: "user:5", expectedToMatch: true, expectedToMatchFilepath: false},
+		{pattern: "user:[abc]", key: "user:b", expectedToMatch: true, expectedToMatchFilepath: true},
+		{pattern: "user:[abc", key: "user:[abc", expectedToMatch: true, expectedToMatchFilepath: false},
+		{pattern: "user:\\*", key: "user:*", expectedToMatch: true, expectedToMatchFilepath: true},
+		{pattern: "user:\\*", key: "user:1", expectedToMatch: false, expectedToMatchFilepath: false},
+		{pattern: "h?llo", key: "héllo", expectedToMatch: true, expectedToMatchFilepath: true},
+		{pattern: "a*b*c", key: "axxbyyc", expectedToMatch: true, expectedToMatchFilepath: true},
+		{pattern: "a*b*c", key: "axxbyy", expectedToMatch: false, expectedToMatchFilepath: false},
+		{pattern: "a*", key: "", expectedToMatch: false, expectedToMatchFilepath: false},
+		{pattern: "", key: "", expectedToMatch: true, expectedToMatchFilepath: true},
+	}
+	for _, scenario := range scenarios {
+		t.Run(scenario.pattern+"---"+scenario.key, func(t *testing.T) {
+			if matched := MatchRedisPattern(scenario.pattern, scenario.key); matched != scenario.expectedToMatch {
+				t.Errorf("expected MatchRedisPattern to return %v, got %v", scenario.expectedToMatch, matched)
+			}
+			if matched := MatchPattern(scenario.pattern, scenario.key); matched != scenario.expectedToMatchFilepath {
+				t.Errorf("expected MatchPattern to return %v, got %v", scenario.expectedToMatchFilepath, matched)
+			}
+		})
+	}
+}
+
+func TestCache_WithPatternDialect(t *testing.T) {
+	for _, withPrefixIndex := range []bool{false, true} {
+		cache := NewCache().WithPatternDialect(RedisGlob).WithPrefixIndex(withPrefixIndex)
+		cache.Set("users/1/profile", "john")
+		cache.Set("users/2/profile", "jane")
+		cache.Set("products/1", "apple")
+		if keys := cache.GetKeysByPattern("users/*", 0); len(keys) != 2 {
+			t.Errorf("expected * to match / with RedisGlob, got %v", keys)
+		}
+		if numberOfKeysDeleted := cache.DeleteKeysByPattern("*/1*"); numberOfKeysDeleted != 2 {
+			t.Errorf("expected 2 keys to have been deleted, got %d", numberOfKeysDeleted)
+		}
+	}
+	cache := NewCache()
+	cache.Set("users/1/profile", "john")
+	if keys := cache.GetKeysByPattern("users/*", 0); len(keys) != 0 {
+		t.Errorf("expected * not to match / with FilepathGlob, got %v", keys)
+	}
+}
+
+func TestCompilePattern(t *testing.T) {
+	scenarios := []struct {
+		pattern string
+		s       string
+	}{
+		{pattern: "*", s: "anything"},
+		{pattern: "*", s: "a/b"},
+		{pattern: "prefix:*", s: "prefix:1"},
+		{pattern: "prefix:*", s: "prefix:"},
+		{pattern: "prefix:*", s: "prefix:a/b"},
+		{pattern: "prefix:*", s: "other:1"},
+		{pattern: "prefix/*", s: "prefix/a/b"},
+		{pattern: "exact", s: "exact"},
+		{pattern: "exact", s: "exactly"},
+		{pattern: "a?c", s: "abc"},
+		{pattern: "*:suffix", s: "a:suffix"},
+		{pattern: "[ab]:*", s: "b:1"},
+		{pattern: "a\\*", s: "a*"},
+	}
+	for _, dialect := range []PatternDialect{FilepathGlob, RedisGlob} {
+		for _, scenario := range scenarios {
+			t.Run(string(dialect)+"_"+scenario.pattern+"_"+scenario.s, func(t *testing.T) {
+				if expected, actual := dialect.Match(scenario.pattern, scenario.s), compilePattern(dialect, scenario.pattern)(scenario.s); expected != actual {
+					t.Errorf("expected %v, got %v", expected, actual)
+				}
+			})
+		}
+	}
+}
+
+func TestLiteralPrefix(t *testing.T) {
+	scenarios := map[string]string{
+		"user:*":   "user:",
+		"user:?":   "user:",
+		"user:[1]": "user:",
+		"user:\\*": "user:",
+		"*":        "",
+		"user:1":   "user:1",
+	}
+	for pattern, expectedPrefix := range scenarios {
+		if prefix := literalPrefix(pattern); prefix != expectedPrefix {
+			t.Errorf("expected literal prefix of %s to be %s, got %s", pattern, expectedPrefix, prefix)
+		}
+	}
+}
@@ -0,0 +1,184 @@
+package gocache
+
+import (
+	"slices"
+	"sort"
+	"strings"
+)
+
+// prefixIndex is a radix tree over the keys of the cache, which makes it possible to retrieve every entry whose key
+// starts with a given prefix in time proportional to the number of such entries, rather than to the number of
+// entries in the cache
+type prefixIndex struct {
+	root radixNode
+}
+
+// radixNode is a node of a prefixIndex
+type radixNode struct {
+	// label is the part of the key that the edge leading to this node represents
+	label string
+
+	// entry is the entry whose key ends at this node, if any
+	entry *Entry
+
+	// children are the children of the node, sorted by the first byte of their label
+	children []*radixNode
+}
+
+// WithPrefixIndex sets whether to maintain an index of the keys ordered by prefix, which allows GetKeysByPattern
+// and DeleteKeysByPattern to go through the keys that start with the literal prefix of the pattern (e.g. "foo:bar:"
+// for "foo:bar:*") instead of going through every key of the cache.
+//
+// This makes every creation and deletion of an entry slightly slower, and uses more memory, but is well worth it
+// for caches containing a large amount of keys on which patterns with a literal prefix are frequently used.
+//
+// Defaults to false
+func (cache *Cache) WithPrefixIndex(enabled bool) *Cache {
+	cache.mutex.Lock()
+	if !enabled {
+		cache.prefixIndex = nil
+	} else if cache.prefixIndex == nil {
+		cache.prefixIndex = &prefixIndex{}
+		for _, entry := range cache.entries {
+			cache.prefixIndex.insert(entry)
+		}
+	}
+	cache.mutex.Unlock()
+	return cache
+}
+
+// forEachEntryMatching calls fn for every entry whose key matches the pattern passed as parameter, until fn returns
+// false
+//
+// Must be called while the cache's lock is held.
+func (cache *Cache) forEachEntryMatching(pattern string, fn func(entry *Entry) bool) {
+	if cache.prefixIndex != nil {
+		if prefix := literalPrefix(pattern); len(prefix) > 0 {
+			cache.prefixIndex.walkPrefix(prefix, func(entry *Entry) bool {
+				if !cache.patternDialect.Match(pattern, entry.Key) {
+					return true
+				}
+				return fn(entry)
+			})
+			return
+		}
+	}
+	for key, entry := range cache.entries {
+		if cache.patternDialect.Match(pattern, key) && !fn(entry) {
+			return
+		}
+	}
+}
+
+// insert adds an entry to the index
+func (index *prefixIndex) insert(entry *Entry) {
+	node, key := &index.root, entry.Key
+	for len(key) > 0 {
+		i, child := node.child(key[0])
+		if child == nil {
+			node.children = slices.Insert(node.children, i, &radixNode{label: key, entry: entry})
+			return
+		}
+		common := commonPrefixLength(key, child.label)
+		if common < len(child.label) {
+			// The key diverges from the child's label, so the child has to be split where they diverge
+			split := &radixNode{label: child.label[:common], children: []*radixNode{child}}
+			child.label = child.label[common:]
+			node.children[i] = split
+			child = split
+		}
+		node, key = child, key[common:]
+	}
+	node.entry = entry
+}
+
+// delete removes the entry with the key passed as parameter from the index
+func (index *prefixIndex) delete(key string) {
+	var parent *radixNode
+	node, position := &index.root, 0
+	for len(key) > 0 {
+		i, child := node.child(key[0])
+		if child == nil || !strings.HasPrefix(key, child.label) {
+			return
+		}
+		parent, node, position = node, child, i
+		key = key[len(child.label):]
+	}
+	node.entry = nil
+	if parent == nil {
+		return
+	}
+	switch len(node.children) {
+	case 0:
+		parent.children = slices.Delete(parent.children, position, position+1)
+		if parent != &index.root && parent.entry == nil && len(parent.children) == 1 {
+			parent.merge()
+		}
+	case 1:
+		node.merge()
+	}
+}
+
+// walkPrefix calls fn for every entry whose key starts with the prefix passed as parameter, until fn returns false
+func (index *prefixIndex) walkPrefix(prefix string, fn func(entry *Entry) bool) {
+	node := &index.root
+	for len(prefix) > 0 {
+		_, child := node.child(prefix[0])
+		if child == nil {
+			return
+		}
+		if strings.HasPrefix(prefix, child.label) {
+			node, prefix = child, prefix[len(child.label):]
+			continue
+		}
+		if !strings.HasPrefix(child.label, prefix) {
+			return
+		}
+		node, prefix = child, ""
+	}
+	node.walk(fn)
+}
+
+// child returns the child whose label starts with the byte passed as parameter, along with its position, or nil
+// along with the position at which such a child would have to be inserted if there is no such child
+func (node *radixNode) child(b byte) (int, *radixNode) {
+	i := sort.Search(len(node.children), func(i int) bool {
+		return node.children[i].label[0] >= b
+	})
+	if i < len(node.children) && node.children[i].label[0] == b {
+		return i, node.children[i]
+	}
+	return i, nil
+}
+
+// merge merges a node without an entry with its only child
+func (node *radixNode) merge() {
+	child := node.children[0]
+	node.label += child.label
+	node.entry = child.entry
+	node.children = child.children
+}
+
+// walk calls fn for the entry of the node and of every one of its descendants, until fn returns false
+//
+// Returns false if fn returned false.
+func (node *radixNode) walk(fn func(entry *Entry) bool) bool {
+	if node.entry != nil && !fn(node.entry) {
+		return false
+	}
+	for _, child := range node.children {
+		if !child.walk(fn) {
+			return false
+		}
+	}
+	return true
+}
+
+// commonPrefixLength returns the length of the longest common prefix of the strings passed as parameter
+func commonPrefixLength(a, b string) int {
+	i := 0
+	for i < len(a) && i < len(b) && a[i] == b[i] {
+		i++
+	}
+	return i
+}
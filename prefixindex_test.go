@@ -0,0 +1,124 @@
+package gocache
+
+import (
+	"fmt"
+	"math/rand"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestCache_WithPrefixIndex(t *testing.T) {
+	cache := NewCache().WithPrefixIndex(true)
+	cache.Set("user:1", "john")
+	cache.Set("user:2", "jane")
+	cache.Set("user:10", "bob")
+	cache.Set("user-archive:1", "alice")
+	cache.Set("product:1", "apple")
+	scenarios := []struct {
+		pattern      string
+		expectedKeys []string
+	}{
+		{pattern: "user:*", expectedKeys: []string{"user:1", "user:10", "user:2"}},
+		{pattern: "user:1*", expectedKeys: []string{"user:1", "user:10"}},
+		{pattern: "user:?", expectedKeys: []string{"user:1", "user:2"}},
+		{pattern: "user*", expectedKeys: []string{"user-archive:1", "user:1", "user:10", "user:2"}},
+		{pattern: "user:1", expectedKeys: []string{"user:1"}},
+		{pattern: "*:1", expectedKeys: []string{"product:1", "user-archive:1", "user:1"}},
+		{pattern: "nope:*", expectedKeys: nil},
+	}
+	for _, scenario := range scenarios {
+		t.Run(scenario.pattern, func(t *testing.T) {
+			keys := cache.GetKeysByPattern(scenario.pattern, 0)
+			slices.Sort(keys)
+			if !slices.Equal(keys, scenario.expectedKeys) {
+				t.Errorf("expected %v, got %v", scenario.expectedKeys, keys)
+			}
+		})
+	}
+	if keys := cache.GetKeysByPattern("user:*", 2); len(keys) != 2 {
+		t.Errorf("expected limit to be respected, got %v", keys)
+	}
+	if numberOfKeysDeleted := cache.DeleteKeysByPattern("user:*"); numberOfKeysDeleted != 3 {
+		t.Errorf("expected 3 keys to have been deleted, got %d", numberOfKeysDeleted)
+	}
+	if keys := cache.GetKeysByPattern("user*", 0); len(keys) != 1 || keys[0] != "user-archive:1" {
+		t.Errorf("expected only user-archive:1 to be left, got %v", keys)
+	}
+	cache.Clear()
+	if keys := cache.GetKeysByPattern("user*", 0); len(keys) != 0 {
+		t.Errorf("expected no keys to be left after clearing the cache, got %v", keys)
+	}
+}
+
+func TestCache_WithPrefixIndexAfterEntriesWereAdded(t *testing.T) {
+	cache := NewCache().WithMaxSize(10)
+	for i := 0; i < 20; i++ {
+		cache.Set(fmt.Sprintf("key:%d", i), i)
+	}
+	cache.WithPrefixIndex(true)
+	if keys := cache.GetKeysByPattern("key:1*", 0); len(keys) != 10 {
+		t.Errorf("expected evicted keys not to be indexed, got %v", keys)
+	}
+	cache.WithPrefixIndex(false)
+	if keys := cache.GetKeysByPattern("key:1*", 0); len(keys) != 10 {
+		t.Errorf("expected %d keys, got %v", 10, keys)
+	}
+}
+
+func TestPrefixIndex(t *testing.T) {
+	index := &prefixIndex{}
+	keys := make(map[string]bool)
+	random := rand.New(rand.NewSource(1))
+	for i := 0; i < 10000; i++ {
+		// Use a small alphabet so that keys share long prefixes and nodes are frequently split and merged
+		key := strings.Repeat("a", random.Intn(3)) + fmt.Sprintf("%b", random.Intn(64))
+		if random.Intn(3) == 0 {
+			index.delete(key)
+			delete(keys, key)
+		} else {
+			index.insert(&Entry{Key: key})
+			keys[key] = true
+		}
+	}
+	for _, prefix := range []string{"", "a", "aa", "aa1", "1", "10", "101", "aa1011", "b"} {
+		var expectedKeys, actualKeys []string
+		for key := range keys {
+			if strings.HasPrefix(key, prefix) {
+				expectedKeys = append(expectedKeys, key)
+			}
+		}
+		index.walkPrefix(prefix, func(entry *Entry) bool {
+			actualKeys = append(actualKeys, entry.Key)
+			return true
+		})
+		slices.Sort(expectedKeys)
+		slices.Sort(actualKeys)
+		if !slices.Equal(expectedKeys, actualKeys) {
+			t.Errorf("expected %v for prefix %q, got %v", expectedKeys, prefix, actualKeys)
+		}
+	}
+	for key := range keys {
+		index.delete(key)
+	}
+	if len(index.root.children) != 0 || index.root.entry != nil {
+		t.Errorf("expected index to be empty, got %+v", index.root)
+	}
+}
+
+func TestPrefixIndex_deleteMergesNodes(t *testing.T) {
+	index := &prefixIndex{}
+	index.insert(&Entry{Key: "abc"})
+	index.insert(&Entry{Key: "abd"})
+	index.insert(&Entry{Key: "ab"})
+	index.delete("ab")
+	index.delete("abc")
+	if len(index.root.children) != 1 || index.root.children[0].label != "abd" || len(index.root.children[0].children) != 0 {
+		t.Errorf("expected nodes to have been merged into a single node, got %+v", index.root.children[0])
+	}
+	index.delete("nope")
+	index.delete("a")
+	if len(index.root.children) != 1 {
+		t.Error("expected deleting keys that don't exist to have no effect")
+	}
+}
@@ -0,0 +1,224 @@
+package gocache
+
+// quota is a limit on the entries whose key matches a given pattern, set through Cache.WithQuota
+type quota struct {
+	// pattern is the pattern that keys must match for their entry to count toward the quota
+	pattern string
+
+	// match returns whether a key matches the quota's pattern
+	match func(key string) bool
+
+	// maxSize is the maximum amount of entries matching the pattern (NoMaxSize means no limit)
+	maxSize int
+
+	// maxMemoryUsage is the maximum amount of memory that can be used by entries matching the pattern
+	// (NoMaxMemoryUsage means no limit)
+	maxMemoryUsage int
+
+	// count is the number of entries that count toward the quota
+	count int
+
+	// memoryUsage is the approximate amount of memory used by the entries that count toward the quota
+	memoryUsage int
+
+	// pinned is the number of entries that count toward the quota, but may not be evicted (see Rule.NoEviction)
+	pinned int
+
+	// evictedKeys is the number of entries that were evicted in order to enforce the quota
+	evictedKeys uint64
+
+	// head and tail are the ends of the list of entries that count toward the quota, which are sorted in the same
+	// order as in the cache, so that the tail is always the entry of the quota that would be evicted first
+	head *Entry
+	tail *Entry
+}
+
+// QuotaUsage is the usage of a quota set through Cache.WithQuota
+type QuotaUsage struct {
+	// Pattern is the pattern that keys must match for their entry to count toward the quota
+	Pattern string
+
+	// MaxSize is the maximum amount of entries matching the pattern
+	MaxSize int
+
+	// MaxMemoryUsage is the maximum amount of memory that can be used by entries matching the pattern
+	MaxMemoryUsage int
+
+	// Count is the number of entries that count toward the quota, including entries that may have already expired,
+	// but have not been removed yet
+	Count int
+
+	// MemoryUsage is the approximate amount of memory used by the entries that count toward the quota
+	MemoryUsage int
+
+	// EvictedKeys is the number of entries that were evicted in order to enforce the quota
+	EvictedKeys uint64
+}
+
+// WithQuota limits the amount of entries and the amount of memory that can be used by entries whose key matches
+// the pattern passed as parameter (see WithPatternDialect). When a quota is exceeded, entries matching the pattern are
+// evicted, starting from the one that would've been evicted first by the eviction policy, until the quota is no
+// longer exceeded. This prevents a single group of keys (e.g. a noisy tenant) from evicting everybody else's
+// entries.
+//
+// Setting maxSize to NoMaxSize or maxMemoryUsageInBytes to NoMaxMemoryUsage disables the corresponding limit.
+//
+// Each entry counts toward at most one quota: the first quota whose pattern matches its key, in the order in which
+// they were added. Note that the cache's MaxSize and MaxMemoryUsage still apply to every entry.
+//
+//	cache := gocache.NewCache().WithMaxSize(100000).
+//		WithQuota("tenant:a:*", 10000, gocache.NoMaxMemoryUsage).
+//		WithQuota("tenant:b:*", gocache.NoMaxSize, 50*1024*1024)
+func (cache *Cache) WithQuota(pattern string, maxSize, maxMemoryUsageInBytes int) *Cache {
+	if maxSize < 0 {
+		maxSize = NoMaxSize
+	}
+	if maxMemoryUsageInBytes < 0 {
+		maxMemoryUsageInBytes = NoMaxMemoryUsage
+	}
+	q := &quota{pattern: pattern, match: compilePattern(cache.patternDialect, pattern), maxSize: maxSize, maxMemoryUsage: maxMemoryUsageInBytes}
+	cache.mutex.Lock()
+	defer cache.unlock()
+	cache.quotas = append(cache.quotas, q)
+	// Go through the entries from the tail so that the entries of the quota end up in the same order as in the cache
+	for entry := cache.tail; entry != nil; entry = entry.previous {
+		if entry.quota() == nil && q.match(entry.Key) {
+			entry.meta().quota = q
+			q.count++
+			q.memoryUsage += entry.size
+			if !entry.evictable() {
+				q.pinned++
+			}
+			q.moveToHead(entry)
+		}
+	}
+	if len(cache.quotas) == 1 && cache.maxMemoryUsage == NoMaxMemoryUsage {
+		// The size of the entries was not tracked until now
+		cache.recalculateMemoryUsage()
+	}
+	cache.evictIfNecessary()
+	return cache
+}
+
+// QuotaUsage returns the usage of every quota set through WithQuota, in the order in which they were added
+func (cache *Cache) QuotaUsage() []QuotaUsage {
+	cache.mutex.RLock()
+	usage := make([]QuotaUsage, 0, len(cache.quotas))
+	for _, q := range cache.quotas {
+		usage = append(usage, QuotaUsage{
+			Pattern:        q.pattern,
+			MaxSize:        q.maxSize,
+			MaxMemoryUsage: q.maxMemoryUsage,
+			Count:          q.count,
+			MemoryUsage:    q.memoryUsage,
+			EvictedKeys:    q.evictedKeys,
+		})
+	}
+	cache.mutex.RUnlock()
+	return usage
+}
+
+// exceeded returns whether the quota's maxSize or maxMemoryUsage is exceeded
+func (q *quota) exceeded() bool {
+	return (q.maxSize != NoMaxSize && q.count > q.maxSize) || (q.maxMemoryUsage != NoMaxMemoryUsage && q.memoryUsage > q.maxMemoryUsage)
+}
+
+// addToQuota makes a newly created entry count toward the first quota whose pattern matches its key, if any
+//
+// Note that the size of the entry is added to the quota's memory usage by updateSize.
+//
+// Must be called while the cache's lock is held.
+func (cache *Cache) addToQuota(entry *Entry) {
+	if q := cache.matchQuota(entry.Key); q != nil {
+		entry.meta().quota = q
+		q.count++
+		q.moveToHead(entry)
+	}
+}
+
+// matchQuota returns the first quota whose pattern matches the key passed as parameter, or nil if there is none
+//
+// Must be called while the cache's lock is held.
+func (cache *Cache) matchQuota(key string) *quota {
+	for _, q := range cache.quotas {
+		if q.match(key) {
+			return q
+		}
+	}
+	return nil
+}
+
+// removeFromQuota stops an entry from counting toward its quota, if any
+//
+// Must be called while the cache's lock is held.
+func (cache *Cache) removeFromQuota(entry *Entry) {
+	q := entry.quota()
+	if q == nil {
+		return
+	}
+	q.count--
+	q.memoryUsage -= entry.size
+	if !entry.evictable() {
+		q.pinned--
+	}
+	q.unlink(entry)
+	entry.metadata.quota = nil
+}
+
+// moveToHead puts an entry that counts toward the quota at the head of the quota's list, which must be done every
+// time the entry is put at the head of the cache
+//
+// Must be called while the cache's lock is held.
+func (q *quota) moveToHead(entry *Entry) {
+	if q.head == entry {
+		return
+	}
+	q.unlink(entry)
+	entry.metadata.quotaNext = q.head
+	if q.head == nil {
+		q.tail = entry
+	} else {
+		q.head.metadata.quotaPrevious = entry
+	}
+	q.head = entry
+}
+
+// unlink removes an entry that counts toward the quota from the quota's list, if it is part of it
+//
+// Must be called while the cache's lock is held.
+func (q *quota) unlink(entry *Entry) {
+	m := entry.metadata
+	if m.quotaPrevious != nil {
+		m.quotaPrevious.metadata.quotaNext = m.quotaNext
+	} else if q.head == entry {
+		q.head = m.quotaNext
+	}
+	if m.quotaNext != nil {
+		m.quotaNext.metadata.quotaPrevious = m.quotaPrevious
+	} else if q.tail == entry {
+		q.tail = m.quotaPrevious
+	}
+	m.quotaNext = nil
+	m.quotaPrevious = nil
+}
+
+// enforceQuotas evicts entries from every quota that is exceeded until it no longer is
+//
+// As with evict, entries that may not be evicted (see Rule.NoEviction) are skipped without being moved.
+//
+// Must be called while the cache's lock is held.
+func (cache *Cache) enforceQuotas() {
+	for _, q := range cache.quotas {
+		// The tail of the quota is the entry of the quota that would be evicted first by the eviction policy
+		for entry := q.tail; entry != nil && q.pinned < q.count && q.exceeded(); {
+			previous := entry.metadata.quotaPrevious
+			if entry.evictable() {
+				cache.stats.EvictedCost += uint64(entry.cost)
+				cache.remove(entry, Evicted)
+				cache.stats.EvictedKeys++
+				q.evictedKeys++
+			}
+			entry = previous
+		}
+	}
+}
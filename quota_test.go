@@ -0,0 +1,147 @@
+package gocache
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestCache_WithQuota(t *testing.T) {
+	cache := NewCache().WithMaxSize(100).WithQuota("tenant:a:*", 10, NoMaxMemoryUsage)
+	for i := 0; i < 50; i++ {
+		cache.Set(fmt.Sprintf("tenant:b:%d", i), i)
+	}
+	// tenant:a is noisy, but it should only be able to evict its own entries
+	for i := 0; i < 1000; i++ {
+		cache.Set(fmt.Sprintf("tenant:a:%d", i), i)
+	}
+	if cache.Count() != 60 {
+		t.Errorf("expected cache to have 60 entries, got %d", cache.Count())
+	}
+	for i := 0; i < 50; i++ {
+		if _, exists := cache.Get(fmt.Sprintf("tenant:b:%d", i)); !exists {
+			t.Errorf("expected tenant:b:%d to still exist", i)
+		}
+	}
+	for i := 990; i < 1000; i++ {
+		if _, exists := cache.Get(fmt.Sprintf("tenant:a:%d", i)); !exists {
+			t.Errorf("expected tenant:a:%d to exist, because it is one of the 10 most recent entries", i)
+		}
+	}
+	usage := cache.QuotaUsage()
+	if len(usage) != 1 {
+		t.Fatalf("expected 1 quota, got %d", len(usage))
+	}
+	if usage[0].Pattern != "tenant:a:*" || usage[0].MaxSize != 10 || usage[0].Count != 10 || usage[0].EvictedKeys != 990 {
+		t.Errorf("unexpected quota usage: %+v", usage[0])
+	}
+	if cache.Stats().EvictedKeys != 990 {
+		t.Errorf("expected 990 evicted keys, got %d", cache.Stats().EvictedKeys)
+	}
+}
+
+func TestCache_WithQuotaAndMaxMemoryUsage(t *testing.T) {
+	cache := NewCache().WithQuota("big:*", NoMaxSize, 1000)
+	cache.Set("small", "value")
+	cache.Set("big:1", strings.Repeat("a", 400))
+	cache.Set("big:2", strings.Repeat("a", 400))
+	if cache.Count() != 3 {
+		t.Errorf("expected cache to have 3 entries, got %d", cache.Count())
+	}
+	// Updating an existing entry with a bigger value must also be taken into consideration
+	cache.Set("big:1", strings.Repeat("a", 600))
+	if _, exists := cache.Get("big:2"); exists {
+		t.Error("expected big:2 to have been evicted, because it was the oldest entry of the quota")
+	}
+	if _, exists := cache.Get("small"); !exists {
+		t.Error("expected small to still exist, because it doesn't count toward the quota")
+	}
+	if usage := cache.QuotaUsage()[0]; usage.Count != 1 || usage.MemoryUsage > 1000 || usage.MemoryUsage < 600 {
+		t.Errorf("unexpected quota usage: %+v", usage)
+	}
+}
+
+func TestCache_WithQuotaWhenKeyMatchesMultipleQuotas(t *testing.T) {
+	cache := NewCache().WithQuota("tenant:a:*", 1, NoMaxMemoryUsage).WithQuota("tenant:*", 2, NoMaxMemoryUsage)
+	cache.Set("tenant:a:1", 1)
+	cache.Set("tenant:a:2", 2)
+	cache.Set("tenant:b:1", 1)
+	cache.Set("tenant:c:1", 1)
+	if cache.Count() != 3 {
+		t.Errorf("expected cache to have 3 entries, got %d", cache.Count())
+	}
+	usage := cache.QuotaUsage()
+	if usage[0].Count != 1 || usage[1].Count != 2 {
+		t.Errorf("expected each entry to count toward the first matching quota only, got %+v", usage)
+	}
+}
+
+func TestCache_WithQuotaAfterEntriesWereAdded(t *testing.T) {
+	cache := NewCache()
+	cache.Set("tenant:a:1", 1)
+	cache.Set("tenant:a:2", 2)
+	cache.WithQuota("tenant:a:*", 1, NoMaxMemoryUsage)
+	if _, exists := cache.Get("tenant:a:1"); exists {
+		t.Error("expected tenant:a:1 to have been evicted")
+	}
+	cache.Delete("tenant:a:2")
+	if usage := cache.QuotaUsage()[0]; usage.Count != 0 || usage.MemoryUsage != 0 {
+		t.Errorf("expected quota to be empty, got %+v", usage)
+	}
+	cache.Set("tenant:a:3", 3)
+	cache.Clear()
+	if usage := cache.QuotaUsage()[0]; usage.Count != 0 || usage.MemoryUsage != 0 {
+		t.Errorf("expected quota to be empty after clearing the cache, got %+v", usage)
+	}
+}
+
+func TestCache_WithQuotaAndLeastRecentlyUsed(t *testing.T) {
+	cache := NewCache().WithEvictionPolicy(LeastRecentlyUsed).
+		WithRule(Rule{Pattern: "tenant:a:pinned", NoEviction: true}).
+		WithQuota("tenant:a:*", 3, NoMaxMemoryUsage)
+	cache.Set("tenant:a:pinned", 0)
+	cache.Set("tenant:a:1", 1)
+	cache.Set("tenant:b:1", 1)
+	cache.Set("tenant:a:2", 2)
+	// Accessing tenant:a:1 makes tenant:a:2 the least recently used entry of the quota that can be evicted
+	cache.Get("tenant:a:1")
+	cache.Set("tenant:a:3", 3)
+	for key, expected := range map[string]bool{"tenant:a:pinned": true, "tenant:a:1": true, "tenant:a:2": false, "tenant:a:3": true, "tenant:b:1": true} {
+		if _, exists := cache.Get(key); exists != expected {
+			t.Errorf("expected %s to exist=%v", key, expected)
+		}
+	}
+	// The list of the quota must be consistent with the cache after clearing it
+	cache.Clear()
+	cache.Set("tenant:a:4", 4)
+	cache.Set("tenant:a:5", 5)
+	cache.Set("tenant:a:6", 6)
+	cache.Set("tenant:a:7", 7)
+	if _, exists := cache.Get("tenant:a:4"); exists {
+		t.Error("expected tenant:a:4 to have been evicted")
+	}
+	if usage := cache.QuotaUsage()[0]; usage.Count != 3 {
+		t.Errorf("expected quota to have 3 entries, got %+v", usage)
+	}
+}
+
+func TestCache_WithQuotaAndNoEvictionDoesNotChangeEvictionOrder(t *testing.T) {
+	cache := NewCache().WithRule(Rule{Pattern: "tenant:a:pinned", NoEviction: true})
+	cache.Set("tenant:a:pinned", 0)
+	cache.Set("tenant:a:1", 1)
+	cache.Set("tenant:b:1", 1)
+	cache.WithQuota("tenant:a:*", 2, NoMaxMemoryUsage)
+	cache.Set("tenant:a:2", 2)
+	var keys []string
+	for key := range cache.EvictionOrder() {
+		keys = append(keys, key)
+	}
+	if expected := []string{"tenant:a:pinned", "tenant:b:1", "tenant:a:2"}; fmt.Sprint(keys) != fmt.Sprint(expected) {
+		t.Errorf("expected eviction order to be %v, got %v", expected, keys)
+	}
+	cache.Delete("tenant:a:2")
+	cache.Set("tenant:a:pinned", 1)
+	if usage := cache.QuotaUsage()[0]; usage.Count != 1 || usage.EvictedKeys != 1 {
+		t.Errorf("expected quota to have 1 entry and 1 evicted key, got %+v", usage)
+	}
+}
@@ -0,0 +1,148 @@
+package gocache
+
+import (
+	"math"
+	"time"
+)
+
+// useDefaultTTL is passed as TTL by functions that don't take a TTL (e.g. Set), and is resolved by Cache.set to the
+// default TTL of the rule matching the key, or to the cache's default TTL if there is no such rule
+const useDefaultTTL time.Duration = math.MinInt64
+
+// Rule is a set of settings that apply to every key matching a given pattern, added through Cache.WithRule
+type Rule struct {
+	// Pattern is the pattern that keys must match for the rule to apply to them (see WithPatternDialect)
+	Pattern string
+
+	// DefaultTTL is the TTL of the entries created or updated without specifying a TTL (e.g. Set), which takes
+	// precedence over the cache's default TTL.
+	//
+	// Defaults to 0, which means that the cache's default TTL is used.
+	DefaultTTL time.Duration
+
+	// MaxTTL is the maximum TTL of the entries, regardless of whether the TTL was specified or not. Longer TTLs,
+	// including NoExpiration, are shortened to MaxTTL.
+	//
+	// Defaults to 0, which means that there is no maximum TTL.
+	MaxTTL time.Duration
+
+	// NoEviction prevents the entries from being evicted as a result of the cache's MaxSize, MaxMemoryUsage or quotas
+	// being exceeded. Entries can still expire, be deleted or be cleared.
+	//
+	// Note that if every entry of the cache matches a rule with NoEviction, the cache may end up exceeding its
+	// MaxSize and MaxMemoryUsage.
+	NoEviction bool
+}
+
+// rule is a Rule along with its compiled pattern
+type rule struct {
+	Rule
+
+	// match returns whether a key matches the rule's pattern
+	match func(key string) bool
+}
+
+// WithRule adds a rule that applies to every key matching the rule's pattern. This makes it possible to configure
+// the default TTL, the maximum TTL and whether entries may be evicted based on the key, rather than at every call
+// site.
+//
+// If multiple rules match the same key, only the first rule that was added applies.
+//
+//	cache := gocache.NewCache().WithMaxSize(10000).
+//		WithRule(gocache.Rule{Pattern: "session:*", DefaultTTL: 30 * time.Minute, NoEviction: true}).
+//		WithRule(gocache.Rule{Pattern: "tmp:*", MaxTTL: time.Minute})
+func (cache *Cache) WithRule(r Rule) *Cache {
+	compiled := &rule{Rule: r, match: compilePattern(cache.patternDialect, r.Pattern)}
+	cache.mutex.Lock()
+	cache.rules = append(cache.rules, compiled)
+	for key, entry := range cache.entries {
+		if entry.rule() == nil && compiled.match(key) {
+			cache.setRule(entry, compiled)
+		}
+	}
+	cache.mutex.Unlock()
+	return cache
+}
+
+// Rules returns every rule added through WithRule, in order of precedence
+func (cache *Cache) Rules() []Rule {
+	cache.mutex.RLock()
+	rules := make([]Rule, 0, len(cache.rules))
+	for _, r := range cache.rules {
+		rules = append(rules, r.Rule)
+	}
+	cache.mutex.RUnlock()
+	return rules
+}
+
+// RuleFor returns the rule that applies to the key passed as parameter, if any
+func (cache *Cache) RuleFor(key string) (Rule, bool) {
+	cache.mutex.RLock()
+	defer cache.mutex.RUnlock()
+	if r := cache.ruleFor(key); r != nil {
+		return r.Rule, true
+	}
+	return Rule{}, false
+}
+
+// ruleFor returns the rule that applies to the key passed as parameter, or nil if there is no such rule
+//
+// Must be called while the cache's lock is held.
+func (cache *Cache) ruleFor(key string) *rule {
+	if entry, ok := cache.entries[key]; ok {
+		return entry.rule()
+	}
+	return cache.matchRule(key)
+}
+
+// matchRule returns the first rule whose pattern matches the key passed as parameter, or nil if there is no such rule
+//
+// Unlike ruleFor, this does not take into consideration the rule that was applied to the existing entry, if any.
+//
+// Must be called while the cache's lock is held.
+func (cache *Cache) matchRule(key string) *rule {
+	for _, r := range cache.rules {
+		if r.match(key) {
+			return r
+		}
+	}
+	return nil
+}
+
+// resolveTTL returns the TTL that should be used to create or update the key passed as parameter, based on the TTL
+// passed as parameter and on the rule that applies to the key
+//
+// Must be called while the cache's lock is held.
+func (cache *Cache) resolveTTL(key string, ttl time.Duration) time.Duration {
+	r := cache.ruleFor(key)
+	if ttl == useDefaultTTL {
+		ttl = cache.defaultTTL
+		if r != nil && r.DefaultTTL > 0 {
+			ttl = r.DefaultTTL
+		}
+	}
+	if r != nil && r.MaxTTL > 0 && (ttl == NoExpiration || ttl > r.MaxTTL) {
+		ttl = r.MaxTTL
+	}
+	return ttl
+}
+
+// setRule makes the rule passed as parameter apply to an entry that has no rule yet, keeping track of the entries
+// that may not be evicted so that eviction doesn't have to go through them when nothing else is left
+//
+// Must be called while the cache's lock is held.
+func (cache *Cache) setRule(entry *Entry, r *rule) {
+	entry.meta().rule = r
+	if r.NoEviction {
+		cache.pinned++
+		if q := entry.quota(); q != nil {
+			q.pinned++
+		}
+	}
+}
+
+// evictable returns whether the entry may be evicted, as opposed to expiring or being deleted
+func (entry *Entry) evictable() bool {
+	r := entry.rule()
+	return r == nil || !r.NoEviction
+}
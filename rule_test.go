@@ -0,0 +1,136 @@
+package gocache
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestCache_WithRule(t *testing.T) {
+	cache := NewCache().WithDefaultTTL(time.Hour).
+		WithRule(Rule{Pattern: "session:*", DefaultTTL: 30 * time.Minute}).
+		WithRule(Rule{Pattern: "tmp:*", MaxTTL: time.Minute}).
+		WithRule(Rule{Pattern: "*", DefaultTTL: 2 * time.Hour})
+	scenarios := []struct {
+		key          string
+		set          func(key string)
+		minTTL       time.Duration
+		maxTTL       time.Duration
+		expectedRule string
+	}{
+		{key: "session:1", set: func(key string) { cache.Set(key, "v") }, minTTL: 29 * time.Minute, maxTTL: 30 * time.Minute, expectedRule: "session:*"},
+		{key: "session:2", set: func(key string) { cache.SetWithTTL(key, "v", 5*time.Hour) }, minTTL: 4 * time.Hour, maxTTL: 5 * time.Hour, expectedRule: "session:*"},
+		{key: "tmp:1", set: func(key string) { cache.Set(key, "v") }, minTTL: 59 * time.Second, maxTTL: time.Minute, expectedRule: "tmp:*"},
+		{key: "tmp:2", set: func(key string) { cache.SetWithTTL(key, "v", NoExpiration) }, minTTL: 59 * time.Second, maxTTL: time.Minute, expectedRule: "tmp:*"},
+		{key: "tmp:3", set: func(key string) { cache.SetWithTTL(key, "v", time.Second) }, minTTL: 0, maxTTL: time.Second, expectedRule: "tmp:*"},
+		{key: "tmp:4", set: func(key string) { cache.SetIfAbsent(key, "v") }, minTTL: 59 * time.Second, maxTTL: time.Minute, expectedRule: "tmp:*"},
+		{key: "tmp:5", set: func(key string) { _, _ = cache.Increment(key, 1) }, minTTL: 59 * time.Second, maxTTL: time.Minute, expectedRule: "tmp:*"},
+		{key: "other", set: func(key string) { cache.Set(key, "v") }, minTTL: 119 * time.Minute, maxTTL: 2 * time.Hour, expectedRule: "*"},
+	}
+	for _, scenario := range scenarios {
+		t.Run(scenario.key, func(t *testing.T) {
+			scenario.set(scenario.key)
+			ttl, err := cache.TTL(scenario.key)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if ttl < scenario.minTTL || ttl > scenario.maxTTL {
+				t.Errorf("expected TTL to be between %s and %s, got %s", scenario.minTTL, scenario.maxTTL, ttl)
+			}
+			if rule, ok := cache.RuleFor(scenario.key); !ok || rule.Pattern != scenario.expectedRule {
+				t.Errorf("expected rule %s to apply, got %+v", scenario.expectedRule, rule)
+			}
+		})
+	}
+	if rules := cache.Rules(); len(rules) != 3 || rules[0].Pattern != "session:*" || rules[2].Pattern != "*" {
+		t.Errorf("expected rules to be returned in order of precedence, got %+v", rules)
+	}
+}
+
+func TestCache_WithRuleAndExpire(t *testing.T) {
+	cache := NewCache().WithRule(Rule{Pattern: "tmp:*", MaxTTL: time.Minute})
+	cache.Set("tmp:1", "v")
+	cache.Expire("tmp:1", time.Hour)
+	if ttl, _ := cache.TTL("tmp:1"); ttl > time.Minute {
+		t.Errorf("expected TTL to have been shortened to a minute, got %s", ttl)
+	}
+	if _, ok := cache.RuleFor("other"); ok {
+		t.Error("expected no rule to apply to other")
+	}
+}
+
+func TestCache_WithRuleAndNoEviction(t *testing.T) {
+	cache := NewCache().WithMaxSize(5).WithRule(Rule{Pattern: "session:*", NoEviction: true})
+	cache.Set("session:1", "v")
+	cache.Set("session:2", "v")
+	for i := 0; i < 10; i++ {
+		cache.Set(fmt.Sprintf("other:%d", i), i)
+	}
+	if cache.Count() != 5 {
+		t.Errorf("expected cache to have 5 entries, got %d", cache.Count())
+	}
+	if _, exists := cache.Get("session:1"); !exists {
+		t.Error("expected session:1 not to have been evicted")
+	}
+	if _, exists := cache.Get("session:2"); !exists {
+		t.Error("expected session:2 not to have been evicted")
+	}
+	for i := 7; i < 10; i++ {
+		if _, exists := cache.Get(fmt.Sprintf("other:%d", i)); !exists {
+			t.Errorf("expected other:%d to exist", i)
+		}
+	}
+	// If nothing can be evicted, the cache exceeds its max size rather than looping forever
+	for i := 3; i < 10; i++ {
+		cache.Set(fmt.Sprintf("session:%d", i), "v")
+	}
+	if cache.Count() != 9 {
+		t.Errorf("expected cache to have 9 entries, got %d", cache.Count())
+	}
+	if cache.Stats().EvictedKeys != 10 {
+		t.Errorf("expected 10 evicted keys, got %d", cache.Stats().EvictedKeys)
+	}
+}
+
+func TestCache_WithRuleAfterEntriesWereAdded(t *testing.T) {
+	cache := NewCache().WithMaxSize(2)
+	cache.Set("pinned", "v")
+	cache.WithRule(Rule{Pattern: "pinned", NoEviction: true})
+	cache.Set("1", "v")
+	cache.Set("2", "v")
+	if _, exists := cache.Get("pinned"); !exists {
+		t.Error("expected rule to apply to entries created before the rule was added")
+	}
+}
+
+func TestCache_WithRuleAndNoEvictionDoesNotChangeEvictionOrder(t *testing.T) {
+	cache := NewCache().WithMaxSize(4).WithRule(Rule{Pattern: "pinned:*", NoEviction: true})
+	cache.Set("pinned:1", "v")
+	cache.Set("1", "v")
+	cache.Set("pinned:2", "v")
+	cache.Set("2", "v")
+	cache.Set("3", "v")
+	cache.Set("4", "v")
+	var keys []string
+	for key := range cache.EvictionOrder() {
+		keys = append(keys, key)
+	}
+	// Pinned entries must stay where they were rather than being moved to the head when they're skipped
+	if expected := []string{"pinned:1", "pinned:2", "3", "4"}; fmt.Sprint(keys) != fmt.Sprint(expected) {
+		t.Errorf("expected eviction order to be %v, got %v", expected, keys)
+	}
+	// Once the only entries left are pinned, eviction gives up instead of going through them
+	cache.Delete("3")
+	cache.Delete("4")
+	cache.Set("pinned:3", "v")
+	cache.Set("pinned:4", "v")
+	cache.Set("pinned:5", "v")
+	if cache.Count() != 5 {
+		t.Errorf("expected cache to have 5 entries, got %d", cache.Count())
+	}
+	cache.Delete("pinned:1")
+	cache.Set("5", "v")
+	if _, exists := cache.Peek("5"); exists {
+		t.Error("expected 5 to have been evicted, since every other entry is pinned")
+	}
+}
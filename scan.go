@@ -0,0 +1,187 @@
+package gocache
+
+import "sort"
+
+// DefaultScanCount is the amount of entries that Scan goes through when the count passed as parameter is 0 or less
+const DefaultScanCount = 10
+
+// minimumTombstonesBeforeCompaction is the minimum number of tombstones in the scan index before it may be compacted,
+// which prevents small caches from being compacted on almost every deletion
+const minimumTombstonesBeforeCompaction = 32
+
+// scanIndex is the list of every entry of the cache ordered by id, which is used by Scan to resume where the previous
+// call left off regardless of how the cache was modified in the meantime
+//
+// Because ids are assigned in increasing order when entries are created, new entries are always appended. Deleted
+// entries are replaced by a tombstone, and tombstones are periodically compacted.
+type scanIndex struct {
+	// ids are the ids of the entries, including the ids of the entries that were deleted since the last compaction
+	ids []uint64
+
+	// entries are the entries, in the same order as ids, with nil in place of the entries that were deleted
+	entries []*Entry
+
+	// tombstones is the number of nil entries
+	tombstones int
+}
+
+// Scan retrieves, out of the next count entries starting from the cursor passed as parameter, the keys that match a
+// given pattern (see WithPatternDialect), along with the cursor that must be passed to the next call to Scan in
+// order to continue scanning the cache. A scan starts with the cursor 0, and is over once the cursor returned is 0.
+//
+// Unlike GetKeysByPattern, which holds the cache's lock while going through every key of the cache, Scan only holds
+// the lock while going through count entries, which means that other operations can take place between calls.
+// In exchange, only the following guarantees are provided when the cache is modified during a scan:
+//   - Every key present from the start to the end of the scan is returned exactly once, even if it is updated
+//   - Keys created or deleted during the scan may or may not be returned
+//   - A key that is deleted and created again during the scan may be returned twice
+//
+// Note that a call may return fewer keys than count, or even no keys at all while the cursor returned isn't 0, as
+// count is the amount of entries to go through, not the amount of keys to return. Expired entries are skipped.
+//
+// The first call to Scan enables the scan index if it isn't already enabled (see WithScanIndex).
+//
+//	var cursor uint64
+//	for {
+//		var keys []string
+//		keys, cursor = cache.Scan(cursor, "user:*", 100)
+//		// ...
+//		if cursor == 0 {
+//			break
+//		}
+//	}
+func (cache *Cache) Scan(cursor uint64, pattern string, count int) ([]string, uint64) {
+	if count <= 0 {
+		count = DefaultScanCount
+	}
+	var keys []string
+	cache.rLockWithScanIndex()
+	entries, next := cache.scan(cursor, count)
+	for _, entry := range entries {
+		if !entry.Expired() && cache.patternDialect.Match(pattern, entry.Key) {
+			keys = append(keys, entry.Key)
+		}
+	}
+	cache.mutex.RUnlock()
+	return keys, next
+}
+
+// WithScanIndex sets whether to maintain the index used by Scan and by the iterators (see All), which is what allows
+// them to resume where they left off regardless of how the cache was modified in the meantime.
+//
+// This makes every creation and deletion of an entry slightly slower, and uses more memory, which is why the index is
+// only maintained once it's needed. If this was not set to true, the index is built the first time Scan or one of the
+// iterators is used, which takes time proportional to the number of entries; enabling it up front avoids that.
+//
+// Disabling the index while a scan or an iteration is in progress voids the guarantees provided by Scan for it.
+//
+// Defaults to false
+func (cache *Cache) WithScanIndex(enabled bool) *Cache {
+	cache.mutex.Lock()
+	if !enabled {
+		cache.scanIndex = nil
+	} else if cache.scanIndex == nil {
+		cache.enableScanIndex()
+	}
+	cache.mutex.Unlock()
+	return cache
+}
+
+// enableScanIndex builds the scan index from the existing entries
+//
+// Must be called while the cache's lock is held.
+func (cache *Cache) enableScanIndex() {
+	cache.scanIndex = &scanIndex{
+		ids:     make([]uint64, 0, len(cache.entries)),
+		entries: make([]*Entry, 0, len(cache.entries)),
+	}
+	for entry := cache.tail; entry != nil; entry = entry.previous {
+		cache.addToScanIndex(entry)
+	}
+}
+
+// rLockWithScanIndex acquires the cache's read lock, after enabling the scan index if it isn't already enabled
+func (cache *Cache) rLockWithScanIndex() {
+	cache.mutex.RLock()
+	if cache.scanIndex != nil {
+		return
+	}
+	cache.mutex.RUnlock()
+	cache.mutex.Lock()
+	if cache.scanIndex == nil {
+		cache.enableScanIndex()
+	}
+	cache.mutex.Unlock()
+	cache.mutex.RLock()
+	// The index may have been disabled in the meantime
+	if cache.scanIndex == nil {
+		cache.mutex.RUnlock()
+		cache.rLockWithScanIndex()
+	}
+}
+
+// scan returns the next count entries starting from the cursor passed as parameter, including expired entries, along
+// with the cursor of the entry that follows them, or 0 if there are no entries left
+//
+// Must be called while the cache's lock is held.
+func (cache *Cache) scan(cursor uint64, count int) ([]*Entry, uint64) {
+	index := cache.scanIndex
+	position := sort.Search(len(index.ids), func(i int) bool {
+		return index.ids[i] >= cursor
+	})
+	entries := make([]*Entry, 0, min(count, len(index.ids)-position))
+	for ; position < len(index.ids) && len(entries) < count; position++ {
+		if entry := index.entries[position]; entry != nil {
+			entries = append(entries, entry)
+		}
+	}
+	if position >= len(index.ids) {
+		return entries, 0
+	}
+	return entries, index.ids[position]
+}
+
+// addToScanIndex assigns an id to an entry and adds it to the scan index
+//
+// Must be called while the cache's lock is held and the scan index is enabled.
+func (cache *Cache) addToScanIndex(entry *Entry) {
+	cache.lastID++
+	entry.meta().id = cache.lastID
+	cache.scanIndex.ids = append(cache.scanIndex.ids, cache.lastID)
+	cache.scanIndex.entries = append(cache.scanIndex.entries, entry)
+}
+
+// removeFromScanIndex replaces an entry by a tombstone in the scan index, and compacts the scan index if at least
+// half of it is made of tombstones, which keeps the cost of compaction amortized over the deletions
+//
+// Must be called while the cache's lock is held and the scan index is enabled.
+func (cache *Cache) removeFromScanIndex(entry *Entry) {
+	index := cache.scanIndex
+	position := sort.Search(len(index.ids), func(i int) bool {
+		return index.ids[i] >= entry.metadata.id
+	})
+	if position == len(index.ids) || index.entries[position] != entry {
+		return
+	}
+	index.entries[position] = nil
+	index.tombstones++
+	if index.tombstones >= minimumTombstonesBeforeCompaction && index.tombstones*2 >= len(index.ids) {
+		index.compact()
+	}
+}
+
+// compact removes every tombstone from the scan index
+func (index *scanIndex) compact() {
+	n := 0
+	for i, entry := range index.entries {
+		if entry != nil {
+			index.ids[n] = index.ids[i]
+			index.entries[n] = entry
+			n++
+		}
+	}
+	clear(index.entries[n:])
+	index.ids = index.ids[:n]
+	index.entries = index.entries[:n]
+	index.tombstones = 0
+}
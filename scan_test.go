@@ -0,0 +1,151 @@
+package gocache
+
+import (
+	"fmt"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestCache_Scan(t *testing.T) {
+	cache := NewCache().WithMaxSize(NoMaxSize)
+	for i := 0; i < 95; i++ {
+		cache.Set(fmt.Sprintf("user:%d", i), i)
+	}
+	for i := 0; i < 5; i++ {
+		cache.Set(fmt.Sprintf("product:%d", i), i)
+	}
+	var keys []string
+	var cursor uint64
+	calls := 0
+	for {
+		var page []string
+		page, cursor = cache.Scan(cursor, "user:*", 10)
+		if len(page) > 10 {
+			t.Errorf("expected at most 10 keys per call, got %d", len(page))
+		}
+		keys = append(keys, page...)
+		calls++
+		if cursor == 0 {
+			break
+		}
+	}
+	if calls != 10 {
+		t.Errorf("expected 10 calls, got %d", calls)
+	}
+	if len(keys) != 95 {
+		t.Errorf("expected 95 keys, got %d", len(keys))
+	}
+	slices.Sort(keys)
+	if len(slices.Compact(keys)) != 95 {
+		t.Error("expected every key to be returned exactly once")
+	}
+}
+
+func TestCache_ScanWithDefaultCount(t *testing.T) {
+	cache := NewCache()
+	for i := 0; i < 25; i++ {
+		cache.Set(fmt.Sprintf("%d", i), i)
+	}
+	keys, cursor := cache.Scan(0, "*", 0)
+	if len(keys) != DefaultScanCount || cursor == 0 {
+		t.Errorf("expected %d keys and a cursor other than 0, got %d keys and cursor %d", DefaultScanCount, len(keys), cursor)
+	}
+	if keys, cursor := cache.Scan(0, "*", 1000); len(keys) != 25 || cursor != 0 {
+		t.Errorf("expected 25 keys and a cursor of 0, got %d keys and cursor %d", len(keys), cursor)
+	}
+	if keys, cursor := NewCache().Scan(0, "*", 10); len(keys) != 0 || cursor != 0 {
+		t.Errorf("expected no keys and a cursor of 0 for an empty cache, got %d keys and cursor %d", len(keys), cursor)
+	}
+}
+
+func TestCache_ScanWithConcurrentModifications(t *testing.T) {
+	cache := NewCache().WithMaxSize(NoMaxSize)
+	for i := 0; i < 1000; i++ {
+		cache.Set(fmt.Sprintf("stable:%d", i), i)
+		cache.Set(fmt.Sprintf("volatile:%d", i), i)
+	}
+	cache.SetWithTTL("expired", "value", time.Nanosecond)
+	time.Sleep(time.Millisecond)
+	seen := make(map[string]int)
+	var cursor uint64
+	for i := 0; ; i++ {
+		var keys []string
+		keys, cursor = cache.Scan(cursor, "*", 50)
+		for _, key := range keys {
+			seen[key]++
+		}
+		if cursor == 0 {
+			break
+		}
+		// Modify the cache between pages: delete volatile keys (which triggers compactions), update stable keys and
+		// create new keys
+		for j := 0; j < 100; j++ {
+			cache.Delete(fmt.Sprintf("volatile:%d", i*100+j))
+		}
+		cache.Set(fmt.Sprintf("stable:%d", i), "updated")
+		cache.Set(fmt.Sprintf("new:%d", i), i)
+	}
+	for i := 0; i < 1000; i++ {
+		if count := seen[fmt.Sprintf("stable:%d", i)]; count != 1 {
+			t.Errorf("expected stable:%d to have been returned exactly once, got %d", i, count)
+		}
+	}
+	if _, ok := seen["expired"]; ok {
+		t.Error("expected expired entries to be skipped")
+	}
+}
+
+func TestCache_WithScanIndex(t *testing.T) {
+	cache := NewCache()
+	cache.Set("1", 1)
+	if cache.scanIndex != nil {
+		t.Error("expected scan index to be disabled by default")
+	}
+	// Scanning enables the index, and entries created before are part of it
+	keys, cursor := cache.Scan(0, "*", 0)
+	if len(keys) != 1 || cursor != 0 {
+		t.Errorf("expected to get key 1 and a cursor of 0, got %v and %d", keys, cursor)
+	}
+	if cache.scanIndex == nil {
+		t.Fatal("expected scan index to have been enabled by Scan")
+	}
+	cache.Set("2", 2)
+	if len(cache.scanIndex.ids) != 2 {
+		t.Errorf("expected scan index to have 2 ids, got %d", len(cache.scanIndex.ids))
+	}
+	cache.WithScanIndex(false)
+	if cache.scanIndex != nil {
+		t.Error("expected scan index to have been disabled")
+	}
+	cache.Set("3", 3)
+	cache.Delete("1")
+	// Iterating enables the index as well
+	count := 0
+	for range cache.All() {
+		count++
+	}
+	if count != 2 {
+		t.Errorf("expected 2 entries, got %d", count)
+	}
+	if cache.scanIndex == nil || len(cache.scanIndex.ids) != 2 {
+		t.Error("expected scan index to have been enabled by All")
+	}
+}
+
+func TestScanIndex_compact(t *testing.T) {
+	cache := NewCache().WithMaxSize(NoMaxSize).WithScanIndex(true)
+	for i := 0; i < 100; i++ {
+		cache.Set(fmt.Sprintf("%d", i), i)
+	}
+	for i := 0; i < 60; i++ {
+		cache.Delete(fmt.Sprintf("%d", i))
+	}
+	if len(cache.scanIndex.ids) != 40+(60-50) || cache.scanIndex.tombstones != 60-50 {
+		t.Errorf("expected scan index to have been compacted once half of it was tombstones, got %d ids and %d tombstones", len(cache.scanIndex.ids), cache.scanIndex.tombstones)
+	}
+	cache.Clear()
+	if len(cache.scanIndex.ids) != 0 {
+		t.Errorf("expected scan index to be empty after clearing the cache, got %d ids", len(cache.scanIndex.ids))
+	}
+}
@@ -0,0 +1,272 @@
+package gocache
+
+import (
+	"reflect"
+	"unsafe"
+)
+
+// entryOverheadInBytes is the approximate size of the fields of an entry other than its key and its value
+const entryOverheadInBytes = 32
+
+// mapHeaderSizeInBytes is the approximate size of the header of a map, excluding its buckets
+const mapHeaderSizeInBytes = 48
+
+// Sizer is the interface that values can implement in order to report their own size, which is useful when a value's
+// size cannot be estimated accurately by walking it (e.g. it references memory that is shared with other values, or
+// memory that is not managed by Go).
+//
+// SizeInBytes must return the approximate number of bytes taken by the value, including everything it references.
+// Because it is called while the cache's lock is held, it must be fast and must not use the cache.
+// It is never called on nil pointers.
+type Sizer interface {
+	SizeInBytes() int
+}
+
+var sizerType = reflect.TypeFor[Sizer]()
+
+// WithSizeFunc sets the function used to compute the size of the values, which is what MaxMemoryUsage and the
+// memory usage of quotas are based on. The size of the key and the overhead of the entry are added to the size
+// returned by the function.
+//
+// By default, or if sizeFunc is nil, the size of a value is the size reported by the value if it implements Sizer,
+// and otherwise, a deep estimation of the memory referenced by the value. Shared references are only counted once,
+// and cycles are supported.
+//
+// If the cache already has entries, their size is recomputed using the new function, and entries are evicted if the
+// cache or its quotas end up exceeding their limits (see RecalculateMemoryUsage).
+func (cache *Cache) WithSizeFunc(sizeFunc func(value any) int) *Cache {
+	cache.mutex.Lock()
+	defer cache.unlock()
+	cache.sizeFunc = sizeFunc
+	if len(cache.entries) > 0 {
+		cache.recalculateMemoryUsage()
+		cache.evictIfNecessary()
+	}
+	return cache
+}
+
+// RecalculateMemoryUsage recomputes the size of every entry, along with the memory usage of the cache and of its
+// quotas, and evicts entries if the cache or its quotas end up exceeding their limits.
+//
+// The size of an entry is computed when its value is written, and the same size is subtracted when the entry is
+// removed, so the memory usage of the cache never drifts. However, this means that if a value is modified in place
+// after being written (e.g. an element is appended to a slice stored in the cache), the memory usage of the cache no
+// longer reflects the size of its values until they are written again, or until this is called.
+//
+// Note that this runs in time proportional to the number of entries, and the size of their values.
+func (cache *Cache) RecalculateMemoryUsage() {
+	cache.mutex.Lock()
+	defer cache.unlock()
+	cache.recalculateMemoryUsage()
+	cache.evictIfNecessary()
+}
+
+// recalculateMemoryUsage recomputes the size of every entry, along with the memory usage of the cache and of its
+// quotas
+//
+// Must be called while the cache's lock is held.
+func (cache *Cache) recalculateMemoryUsage() {
+	cache.addMemoryUsage(-cache.memoryUsage)
+	for _, q := range cache.quotas {
+		q.memoryUsage = 0
+	}
+	tracksEntrySizes := cache.tracksEntrySizes()
+	for _, entry := range cache.entries {
+		entry.size = 0
+		if tracksEntrySizes {
+			cache.updateSize(entry, cache.sizeOf(entry.Key, entry.Value))
+		}
+	}
+}
+
+// tracksMemoryUsage returns whether the cache needs to keep track of its memory usage, which is only the case if the
+// cache has a MaxMemoryUsage or is part of a Budget
+func (cache *Cache) tracksMemoryUsage() bool {
+	return cache.maxMemoryUsage != NoMaxMemoryUsage || cache.budget != nil
+}
+
+// tracksEntrySizes returns whether the cache needs to know the size of its entries, which is only the case if the
+// cache keeps track of its memory usage or has quotas
+func (cache *Cache) tracksEntrySizes() bool {
+	return cache.tracksMemoryUsage() || len(cache.quotas) > 0
+}
+
+// addMemoryUsage adds a delta to the memory usage of the cache, and to the memory usage of its Budget, if any
+//
+// Must be called while the cache's lock is held.
+func (cache *Cache) addMemoryUsage(delta int) {
+	cache.memoryUsage += delta
+	if cache.budget != nil {
+		cache.budget.memoryUsage.Add(int64(delta))
+	}
+}
+
+// updateSize sets the size of an entry whose value was written, and updates the memory usage of the cache and of the
+// entry's quota accordingly
+//
+// Must be called while the cache's lock is held.
+func (cache *Cache) updateSize(entry *Entry, size int) {
+	if !cache.tracksEntrySizes() {
+		return
+	}
+	if cache.tracksMemoryUsage() {
+		cache.addMemoryUsage(size - entry.size)
+	}
+	if q := entry.quota(); q != nil {
+		q.memoryUsage += size - entry.size
+	}
+	entry.size = size
+}
+
+// sizeOf returns the size of an entry with the key and value passed as parameter in bytes, approximately, using the
+// cache's sizeFunc if there is one
+//
+// Must be called while the cache's lock is held.
+func (cache *Cache) sizeOf(key string, value any) int {
+	if cache.sizeFunc == nil {
+		return toBytes(key) + toBytes(value) + entryOverheadInBytes
+	}
+	return toBytes(key) + cache.sizeFunc(value) + entryOverheadInBytes
+}
+
+// estimateSize returns the approximate number of bytes taken by a value, including everything it references
+func estimateSize(value any) int {
+	size := int(unsafe.Sizeof(value))
+	if value == nil {
+		return size
+	}
+	v := reflect.ValueOf(value)
+	if !isPointerShaped(v.Kind()) {
+		// Values that aren't pointer-shaped are stored outside the interface
+		size += int(v.Type().Size())
+	}
+	return size + (&sizeEstimator{}).referencedSize(v)
+}
+
+// sizeEstimator walks values in order to estimate their size
+type sizeEstimator struct {
+	// seen are the addresses that were already counted, which prevents shared references from being counted more
+	// than once, and cycles from leading to infinite recursion
+	seen map[uintptr]struct{}
+}
+
+// visit marks an address as seen, and returns whether it had not been seen before
+func (estimator *sizeEstimator) visit(address uintptr) bool {
+	if estimator.seen == nil {
+		estimator.seen = make(map[uintptr]struct{})
+	} else if _, seen := estimator.seen[address]; seen {
+		return false
+	}
+	estimator.seen[address] = struct{}{}
+	return true
+}
+
+// referencedSize returns the size of the memory referenced by a value, excluding the size of the value itself
+func (estimator *sizeEstimator) referencedSize(v reflect.Value) int {
+	switch v.Kind() {
+	case reflect.String:
+		return v.Len()
+	case reflect.Pointer:
+		if v.IsNil() || !estimator.visit(v.Pointer()) {
+			return 0
+		}
+		if sizer, ok := asSizer(v); ok {
+			return sizer.SizeInBytes()
+		}
+		return int(v.Type().Elem().Size()) + estimator.referencedSize(v.Elem())
+	case reflect.Interface:
+		if v.IsNil() {
+			return 0
+		}
+		v = v.Elem()
+		if sizer, ok := asSizer(v); ok && !isPointerShaped(v.Kind()) {
+			return sizer.SizeInBytes()
+		}
+		size := estimator.referencedSize(v)
+		if !isPointerShaped(v.Kind()) {
+			size += int(v.Type().Size())
+		}
+		return size
+	case reflect.Slice:
+		if v.IsNil() || !estimator.visit(v.Pointer()) {
+			return 0
+		}
+		return v.Cap()*int(v.Type().Elem().Size()) + estimator.elementsSize(v)
+	case reflect.Array:
+		return estimator.elementsSize(v)
+	case reflect.Struct:
+		size := 0
+		for i := 0; i < v.NumField(); i++ {
+			size += estimator.referencedSize(v.Field(i))
+		}
+		return size
+	case reflect.Map:
+		if v.IsNil() || !estimator.visit(v.Pointer()) {
+			return 0
+		}
+		size := mapHeaderSizeInBytes + v.Len()*int(v.Type().Key().Size()+v.Type().Elem().Size())
+		iterator := v.MapRange()
+		for iterator.Next() {
+			size += estimator.referencedSize(iterator.Key()) + estimator.referencedSize(iterator.Value())
+		}
+		return size
+	case reflect.Chan:
+		if v.IsNil() || !estimator.visit(v.Pointer()) {
+			return 0
+		}
+		return v.Cap() * int(v.Type().Elem().Size())
+	default:
+		// Booleans and numbers don't reference anything, and the memory referenced by functions and unsafe
+		// pointers cannot be estimated
+		return 0
+	}
+}
+
+// elementsSize returns the size of the memory referenced by the elements of a slice or an array
+func (estimator *sizeEstimator) elementsSize(v reflect.Value) int {
+	if !mayReference(v.Type().Elem()) {
+		return 0
+	}
+	size := 0
+	for i := 0; i < v.Len(); i++ {
+		size += estimator.referencedSize(v.Index(i))
+	}
+	return size
+}
+
+// asSizer returns the value as a Sizer if it implements the Sizer interface and can be accessed
+func asSizer(v reflect.Value) (Sizer, bool) {
+	if !v.Type().Implements(sizerType) || !v.CanInterface() {
+		return nil, false
+	}
+	return v.Interface().(Sizer), true
+}
+
+// isPointerShaped returns whether values of a given kind are stored directly in an interface
+func isPointerShaped(kind reflect.Kind) bool {
+	switch kind {
+	case reflect.Pointer, reflect.Map, reflect.Chan, reflect.Func, reflect.UnsafePointer:
+		return true
+	}
+	return false
+}
+
+// mayReference returns whether values of a given type may reference memory outside of themselves
+func mayReference(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.Bool, reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64, reflect.Complex64, reflect.Complex128, reflect.Func, reflect.UnsafePointer:
+		return false
+	case reflect.Array:
+		return mayReference(t.Elem())
+	case reflect.Struct:
+		for i := 0; i < t.NumField(); i++ {
+			if mayReference(t.Field(i).Type) {
+				return true
+			}
+		}
+		return false
+	}
+	return true
+}
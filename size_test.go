@@ -0,0 +1,207 @@
+package gocache
+
+import (
+	"fmt"
+	"math/rand"
+	"strings"
+	"testing"
+	"time"
+	"unsafe"
+)
+
+type sizedValue struct {
+	data []byte
+}
+
+func (v sizedValue) SizeInBytes() int {
+	return 1000
+}
+
+type node struct {
+	Name string
+	Next *node
+}
+
+func TestEstimateSize(t *testing.T) {
+	interfaceSize := int(unsafe.Sizeof(any(nil)))
+	first := &node{Name: "first"}
+	second := &node{Name: "second", Next: first}
+	first.Next = second
+	nodeSize := int(unsafe.Sizeof(node{}))
+	shared := &node{Name: "shared"}
+	scenarios := []struct {
+		name         string
+		value        any
+		expectedSize int
+	}{
+		{name: "nil", value: nil, expectedSize: interfaceSize},
+		{name: "struct", value: struct{ A, B string }{A: "hello", B: "world"}, expectedSize: interfaceSize + 32 + 10},
+		{name: "pointer", value: &node{Name: "hello"}, expectedSize: interfaceSize + nodeSize + 5},
+		{name: "cycle", value: first, expectedSize: interfaceSize + 2*nodeSize + len("first") + len("second")},
+		{name: "shared-pointer", value: []*node{shared, shared}, expectedSize: interfaceSize + 24 + 2*8 + nodeSize + len("shared")},
+		{name: "map", value: map[string]int{"a": 1, "bc": 2}, expectedSize: interfaceSize + mapHeaderSizeInBytes + 2*(16+8) + 3},
+		{name: "nested-slices", value: [][]int{{1, 2}, {3}}, expectedSize: interfaceSize + 24 + 2*24 + 3*8},
+		{name: "interface-field", value: struct{ A any }{A: "hello"}, expectedSize: interfaceSize + 16 + 16 + 5},
+	}
+	for _, scenario := range scenarios {
+		t.Run(scenario.name, func(t *testing.T) {
+			if size := estimateSize(scenario.value); size != scenario.expectedSize {
+				t.Errorf("expected %d, got %d", scenario.expectedSize, size)
+			}
+		})
+	}
+}
+
+func TestEstimateSize_Sizer(t *testing.T) {
+	interfaceSize := int(unsafe.Sizeof(any(nil)))
+	if size := toBytes(sizedValue{data: make([]byte, 5)}); size != interfaceSize+1000 {
+		t.Errorf("expected the size reported by the value to be used, got %d", size)
+	}
+	if size := estimateSize(struct{ A any }{A: sizedValue{}}); size != interfaceSize+16+1000 {
+		t.Errorf("expected the size reported by a nested value to be used, got %d", size)
+	}
+}
+
+func TestCache_SetWithNilPointerToSizer(t *testing.T) {
+	cache := NewCache().WithForceNilInterfaceOnNilPointer(false).WithMaxMemoryUsage(1000)
+	// sizedValue has a value receiver, so calling SizeInBytes on a nil *sizedValue would panic
+	cache.Set("key", (*sizedValue)(nil))
+	if value, exists := cache.Get("key"); !exists || value != (*sizedValue)(nil) {
+		t.Errorf("expected the nil pointer to have been stored, got %v", value)
+	}
+}
+
+func TestCache_WithSizeFunc(t *testing.T) {
+	cache := NewCache().WithMaxMemoryUsage(Kilobyte).WithSizeFunc(func(value any) int {
+		return 100
+	})
+	cache.Set("k", "v")
+	if expectedMemoryUsage := toBytes("k") + 100 + entryOverheadInBytes; cache.MemoryUsage() != expectedMemoryUsage {
+		t.Errorf("expected memory usage to be %d, got %d", expectedMemoryUsage, cache.MemoryUsage())
+	}
+	for i := 0; i < 10; i++ {
+		cache.Set(string(rune('a'+i)), i)
+	}
+	if cache.Count() != 6 {
+		t.Errorf("expected entries to have been evicted based on the size returned by the size function, got %d entries", cache.Count())
+	}
+}
+
+func TestCache_MemoryUsageMatchesSizeOfEntries(t *testing.T) {
+	cache := NewCache().WithMaxSize(50).WithMaxMemoryUsage(4*Kilobyte).WithQuota("quota:*", 10, 512)
+	random := rand.New(rand.NewSource(1))
+	var mutable []int
+	for i := 0; i < 5000; i++ {
+		key := fmt.Sprintf("key:%d", random.Intn(100))
+		if random.Intn(4) == 0 {
+			key = fmt.Sprintf("quota:%d", random.Intn(20))
+		}
+		switch random.Intn(8) {
+		case 0:
+			cache.Delete(key)
+		case 1:
+			cache.SetWithTTL(key, strings.Repeat("a", random.Intn(100)), time.Duration(random.Intn(2))*time.Nanosecond)
+		case 2:
+			_, _ = cache.Increment(key, 1)
+		case 3:
+			cache.SetAll(map[string]any{key: random.Intn(1000), key + ":other": []string{"a", "b"}})
+		case 4:
+			// Values modified in place after being written must not make the memory usage drift
+			mutable = append(mutable, i)
+			cache.Set(key, mutable)
+		case 5:
+			cache.GetAndDelete(key)
+		default:
+			cache.Set(key, strings.Repeat("b", random.Intn(200)))
+		}
+		cache.mutex.RLock()
+		memoryUsage, quotaMemoryUsage := 0, 0
+		for _, entry := range cache.entries {
+			memoryUsage += entry.size
+			if entry.quota() != nil {
+				quotaMemoryUsage += entry.size
+			}
+		}
+		if memoryUsage != cache.memoryUsage || quotaMemoryUsage != cache.quotas[0].memoryUsage {
+			t.Fatalf("expected memory usage to be %d and quota memory usage to be %d after %d operations, got %d and %d", memoryUsage, quotaMemoryUsage, i+1, cache.memoryUsage, cache.quotas[0].memoryUsage)
+		}
+		cache.mutex.RUnlock()
+	}
+	cache.RecalculateMemoryUsage()
+	cache.mutex.RLock()
+	memoryUsage := 0
+	for _, entry := range cache.entries {
+		memoryUsage += cache.sizeOf(entry.Key, entry.Value)
+	}
+	cache.mutex.RUnlock()
+	if memoryUsage != cache.MemoryUsage() {
+		t.Errorf("expected memory usage to be %d after recalculating it, got %d", memoryUsage, cache.MemoryUsage())
+	}
+}
+
+func TestCache_RecalculateMemoryUsage(t *testing.T) {
+	type document struct {
+		Data []byte
+	}
+	cache := NewCache().WithMaxMemoryUsage(2 * Kilobyte)
+	value := &document{Data: make([]byte, 10)}
+	cache.Set("a", "value")
+	cache.Set("b", value)
+	memoryUsage := cache.MemoryUsage()
+	value.Data = make([]byte, 1010)
+	if cache.MemoryUsage() != memoryUsage {
+		t.Error("expected memory usage not to change when a value is modified in place")
+	}
+	cache.RecalculateMemoryUsage()
+	if cache.MemoryUsage() != memoryUsage+1000 {
+		t.Errorf("expected memory usage to be %d after recalculating it, got %d", memoryUsage+1000, cache.MemoryUsage())
+	}
+	cache.Delete("b")
+	if expectedMemoryUsage := (&Entry{Key: "a", Value: "value"}).SizeInBytes(); cache.MemoryUsage() != expectedMemoryUsage {
+		t.Errorf("expected memory usage to be %d, got %d", expectedMemoryUsage, cache.MemoryUsage())
+	}
+	value.Data = make([]byte, 10)
+	cache.Set("b", value)
+	value.Data = make([]byte, 5000)
+	cache.RecalculateMemoryUsage()
+	if _, exists := cache.Peek("a"); exists || cache.Count() != 0 {
+		t.Errorf("expected entries to have been evicted once the memory usage was recalculated, got %d entries", cache.Count())
+	}
+}
+
+func TestCache_WithMaxMemoryUsageAfterEntriesWereAdded(t *testing.T) {
+	cache := NewCache()
+	cache.Set("a", "value")
+	cache.WithMaxMemoryUsage(Kilobyte)
+	if expectedMemoryUsage := (&Entry{Key: "a", Value: "value"}).SizeInBytes(); cache.MemoryUsage() != expectedMemoryUsage {
+		t.Errorf("expected memory usage to be %d, got %d", expectedMemoryUsage, cache.MemoryUsage())
+	}
+	cache.Delete("a")
+	if cache.MemoryUsage() != 0 {
+		t.Errorf("expected memory usage to be 0, got %d", cache.MemoryUsage())
+	}
+}
+
+func TestCache_WithMaxMemoryUsageEvictsExistingEntries(t *testing.T) {
+	var evicted []string
+	cache := NewCache().WithMaxSize(NoMaxSize).WithOnEvicted(func(key string, value any, reason RemovalReason) {
+		evicted = append(evicted, key)
+	})
+	for i := 0; i < 10; i++ {
+		cache.Set(string(rune('a'+i)), strings.Repeat("a", 100))
+	}
+	cache.WithMaxMemoryUsage(500)
+	if cache.MemoryUsage() > 500 {
+		t.Errorf("expected memory usage to be at most 500, got %d", cache.MemoryUsage())
+	}
+	if len(evicted) != 10-cache.Count() {
+		t.Errorf("expected the onEvicted callback to have been called for the %d evicted entries, got %d", 10-cache.Count(), len(evicted))
+	}
+	// Making the entries larger must evict entries as well
+	cache.WithSizeFunc(func(value any) int {
+		return 200
+	})
+	if cache.MemoryUsage() > 500 || cache.Count() != 2 {
+		t.Errorf("expected only 2 entries to be left, got %d entries using %d bytes", cache.Count(), cache.MemoryUsage())
+	}
+}
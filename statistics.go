@@ -12,4 +12,21 @@ type Statistics struct {
 
 	// Misses is the number of cache misses
 	Misses uint64
+
+	// TotalCost is the sum of the cost of every entry currently in the cache (see Cache.WithMaxCost)
+	TotalCost int
+
+	// EvictedCost is the sum of the cost of every entry that was evicted
+	EvictedCost uint64
+
+	// EffectiveMaxMemoryUsage is the MaxMemoryUsage currently enforced, which is adjusted by the memory pressure
+	// monitor while it's running (see Cache.StartMemoryPressureMonitor)
+	EffectiveMaxMemoryUsage int
+
+	// MemoryLimitChangeReason is the reason why the memory pressure monitor last changed EffectiveMaxMemoryUsage, or
+	// an empty string if it didn't
+	MemoryLimitChangeReason MemoryLimitChangeReason
+
+	// DroppedEvents is the number of events that could not be sent to a subscriber because its buffer was full
+	DroppedEvents uint64
 }
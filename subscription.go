@@ -0,0 +1,206 @@
+package gocache
+
+import (
+	"slices"
+	"sync"
+	"sync/atomic"
+)
+
+// EventMask is a set of event types that a subscriber is interested in. Event types can be combined using a bitwise OR,
+// e.g. EventSet|EventDelete
+type EventMask uint
+
+const (
+	// EventSet is the event type emitted when an entry is created or updated, including when only its expiration time
+	// is changed through Expire
+	EventSet EventMask = 1 << iota
+
+	// EventDelete is the event type emitted when an entry is explicitly deleted
+	EventDelete
+
+	// EventExpire is the event type emitted when an expired entry is removed
+	EventExpire
+
+	// EventEvict is the event type emitted when an entry is evicted
+	EventEvict
+
+	// EventClear is the event type emitted for each entry removed as a result of Clear being called
+	EventClear
+
+	// AllEvents is an EventMask that matches every event type
+	AllEvents = EventSet | EventDelete | EventExpire | EventEvict | EventClear
+)
+
+// DefaultSubscriptionBufferSize is the default number of events that can be buffered for each subscriber
+const DefaultSubscriptionBufferSize = 128
+
+// SubscriberOverflowPolicy dictates what happens when an event is published to a subscriber whose buffer is full
+type SubscriberOverflowPolicy string
+
+const (
+	// DropEvents is a SubscriberOverflowPolicy that causes events to be dropped if the subscriber's buffer is full.
+	// Every dropped event is counted in Statistics.DroppedEvents.
+	DropEvents SubscriberOverflowPolicy = "DropEvents"
+
+	// BlockUntilDelivered is a SubscriberOverflowPolicy that causes the goroutine that triggered the event to block
+	// until the subscriber has room in its buffer or the subscription is cancelled.
+	//
+	// Note that events are published after the cache's lock is released, so a slow subscriber only slows down the
+	// goroutine that performed the operation, not every operation on the cache.
+	BlockUntilDelivered SubscriberOverflowPolicy = "BlockUntilDelivered"
+)
+
+// Event is a mutation that happened on the cache
+type Event struct {
+	// Type is the type of the event. Unlike the EventMask passed to Subscribe, only a single bit is ever set.
+	Type EventMask
+
+	// Key is the key of the entry affected by the event
+	Key string
+
+	// Value is the value of the entry. For EventSet, this is the new value, and for every other event type, this
+	// is the value that the entry had when it was removed.
+	Value any
+}
+
+// subscription is a subscriber registered through Cache.Subscribe
+type subscription struct {
+	pattern        string
+	match          func(key string) bool
+	events         EventMask
+	overflowPolicy SubscriberOverflowPolicy
+	channel        chan Event
+
+	// mutex is read-locked while publishing and write-locked when closing channel, which prevents the channel from
+	// being closed while an event is being sent to it
+	mutex  sync.RWMutex
+	done   chan struct{}
+	closed bool
+}
+
+// Subscribe returns a channel on which every event that matches both the pattern (see WithPatternDialect) and the
+// EventMask passed as parameter will be sent, as well as a function to cancel the subscription.
+//
+// Events are buffered up to the size configured by Cache.WithSubscriptionBufferSize. What happens when that buffer
+// is full is dictated by Cache.WithSubscriberOverflowPolicy.
+//
+// Events are published after the cache's lock is released, which means that events triggered by different
+// goroutines at the same time may be received in a different order than the order in which they were applied.
+//
+// Calling the cancel function closes the channel. It is safe to call it more than once.
+//
+//	events, cancel := cache.Subscribe("user:*", gocache.EventSet|gocache.EventDelete)
+//	defer cancel()
+//	for event := range events {
+//		fmt.Println(event.Type, event.Key)
+//	}
+func (cache *Cache) Subscribe(pattern string, events EventMask) (<-chan Event, func()) {
+	cache.mutex.Lock()
+	s := &subscription{
+		pattern:        pattern,
+		match:          compilePattern(cache.patternDialect, pattern),
+		events:         events,
+		overflowPolicy: cache.subscriberOverflowPolicy,
+		channel:        make(chan Event, cache.subscriptionBufferSize),
+		done:           make(chan struct{}),
+	}
+	// The slice is copied rather than modified in place, because Cache.unlock publishes events to a copy of the
+	// subscriptions taken while the lock was held
+	cache.subscriptions = append(slices.Clone(cache.subscriptions), s)
+	cache.mutex.Unlock()
+	var once sync.Once
+	return s.channel, func() {
+		once.Do(func() {
+			cache.mutex.Lock()
+			cache.subscriptions = slices.DeleteFunc(slices.Clone(cache.subscriptions), func(other *subscription) bool {
+				return other == s
+			})
+			cache.mutex.Unlock()
+			s.close()
+		})
+	}
+}
+
+// WithSubscriptionBufferSize sets the number of events that can be buffered for each subscription created by
+// Cache.Subscribe. Only subscriptions created after calling this function are affected.
+//
+// Defaults to DefaultSubscriptionBufferSize
+func (cache *Cache) WithSubscriptionBufferSize(size int) *Cache {
+	if size < 0 {
+		size = 0
+	}
+	cache.subscriptionBufferSize = size
+	return cache
+}
+
+// WithSubscriberOverflowPolicy sets what happens when an event is published to a subscriber whose buffer is full.
+// Only subscriptions created after calling this function are affected.
+//
+// Defaults to DropEvents
+func (cache *Cache) WithSubscriberOverflowPolicy(policy SubscriberOverflowPolicy) *Cache {
+	cache.subscriberOverflowPolicy = policy
+	return cache
+}
+
+// queueEvent records an event in the change log, if enabled, and keeps track of it so that it can be published to the
+// subscribers once the lock is released.
+//
+// Must be called while the cache's lock is held.
+func (cache *Cache) queueEvent(eventType EventMask, entry *Entry) {
+	if cache.changeLog != nil {
+		cache.changeLog.append(eventType, entry)
+	}
+	if len(cache.subscriptions) == 0 {
+		return
+	}
+	cache.pendingEvents = append(cache.pendingEvents, Event{Type: eventType, Key: entry.Key, Value: entry.Value})
+}
+
+// publish sends an event to the subscriber if the event matches the subscription
+func (s *subscription) publish(cache *Cache, event Event) {
+	if s.events&event.Type == 0 || !s.match(event.Key) {
+		return
+	}
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	if s.closed {
+		return
+	}
+	if s.overflowPolicy == BlockUntilDelivered {
+		select {
+		case s.channel <- event:
+		case <-s.done:
+		}
+		return
+	}
+	select {
+	case s.channel <- event:
+	default:
+		atomic.AddUint64(&cache.stats.DroppedEvents, 1)
+	}
+}
+
+// close closes the subscription's channel
+func (s *subscription) close() {
+	// Closing done first unblocks any publish waiting for room in the buffer, which is necessary
+	// to be able to acquire the write lock
+	close(s.done)
+	s.mutex.Lock()
+	s.closed = true
+	close(s.channel)
+	s.mutex.Unlock()
+}
+
+// eventType returns the EventMask corresponding to the RemovalReason
+func (reason RemovalReason) eventType() EventMask {
+	switch reason {
+	case Evicted:
+		return EventEvict
+	case Expired:
+		return EventExpire
+	case Cleared:
+		return EventClear
+	default:
+		return EventDelete
+	}
+}
@@ -0,0 +1,132 @@
+package gocache
+
+import (
+	"testing"
+	"time"
+)
+
+func receiveEvent(t *testing.T, events <-chan Event) Event {
+	t.Helper()
+	select {
+	case event := <-events:
+		return event
+	case <-time.After(time.Second):
+		t.Fatal("expected to receive an event")
+	}
+	return Event{}
+}
+
+func TestCache_Subscribe(t *testing.T) {
+	cache := NewCache().WithMaxSize(2)
+	events, cancel := cache.Subscribe("*", AllEvents)
+	defer cancel()
+	cache.Set("1", "v1")
+	cache.Set("1", "v2")
+	cache.Set("2", "v3")
+	cache.Set("3", "v4")
+	cache.Delete("2")
+	cache.SetWithTTL("4", "v5", time.Nanosecond)
+	time.Sleep(time.Millisecond)
+	cache.Get("4")
+	cache.Clear()
+	expectedEvents := []Event{
+		{Type: EventSet, Key: "1", Value: "v1"},
+		{Type: EventSet, Key: "1", Value: "v2"},
+		{Type: EventSet, Key: "2", Value: "v3"},
+		{Type: EventSet, Key: "3", Value: "v4"},
+		{Type: EventEvict, Key: "1", Value: "v2"},
+		{Type: EventDelete, Key: "2", Value: "v3"},
+		{Type: EventSet, Key: "4", Value: "v5"},
+		{Type: EventExpire, Key: "4", Value: "v5"},
+		{Type: EventClear, Key: "3", Value: "v4"},
+	}
+	for _, expectedEvent := range expectedEvents {
+		if event := receiveEvent(t, events); event != expectedEvent {
+			t.Errorf("expected %v, got %v", expectedEvent, event)
+		}
+	}
+}
+
+func TestCache_SubscribeWithPatternAndEventMask(t *testing.T) {
+	cache := NewCache()
+	events, cancel := cache.Subscribe("user:*", EventDelete)
+	defer cancel()
+	cache.Set("user:1", "john")
+	cache.Set("product:1", "apple")
+	cache.Delete("product:1")
+	cache.Delete("user:1")
+	if event := receiveEvent(t, events); event.Type != EventDelete || event.Key != "user:1" {
+		t.Errorf("expected the only event received to be the deletion of user:1, got %v", event)
+	}
+	if len(events) != 0 {
+		t.Errorf("expected no other events, but %d events are buffered", len(events))
+	}
+}
+
+func TestCache_SubscribeWithDropEvents(t *testing.T) {
+	cache := NewCache().WithSubscriptionBufferSize(2).WithSubscriberOverflowPolicy(DropEvents)
+	events, cancel := cache.Subscribe("*", EventSet)
+	defer cancel()
+	for _, key := range []string{"1", "2", "3", "4", "5"} {
+		cache.Set(key, key)
+	}
+	if len(events) != 2 {
+		t.Errorf("expected 2 events to be buffered, got %d", len(events))
+	}
+	if droppedEvents := cache.Stats().DroppedEvents; droppedEvents != 3 {
+		t.Errorf("expected 3 dropped events, got %d", droppedEvents)
+	}
+	if event := receiveEvent(t, events); event.Key != "1" {
+		t.Errorf("expected the oldest event to be kept, got %v", event)
+	}
+}
+
+func TestCache_SubscribeWithBlockUntilDelivered(t *testing.T) {
+	cache := NewCache().WithSubscriptionBufferSize(1).WithSubscriberOverflowPolicy(BlockUntilDelivered)
+	events, cancel := cache.Subscribe("*", EventSet)
+	defer cancel()
+	done := make(chan struct{})
+	go func() {
+		cache.Set("1", "v1")
+		cache.Set("2", "v2")
+		close(done)
+	}()
+	select {
+	case <-done:
+		t.Fatal("expected the second Set to block until the subscriber has room in its buffer")
+	case <-time.After(50 * time.Millisecond):
+	}
+	// The cache itself must not be locked while a publisher is blocked
+	if _, exists := cache.Get("2"); !exists {
+		t.Error("expected key 2 to exist")
+	}
+	receiveEvent(t, events)
+	receiveEvent(t, events)
+	<-done
+	if droppedEvents := cache.Stats().DroppedEvents; droppedEvents != 0 {
+		t.Errorf("expected no dropped events, got %d", droppedEvents)
+	}
+}
+
+func TestCache_SubscribeCancelUnblocksPublisher(t *testing.T) {
+	cache := NewCache().WithSubscriptionBufferSize(0).WithSubscriberOverflowPolicy(BlockUntilDelivered)
+	events, cancel := cache.Subscribe("*", AllEvents)
+	done := make(chan struct{})
+	go func() {
+		cache.Set("1", "v1")
+		close(done)
+	}()
+	time.Sleep(10 * time.Millisecond)
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("cancelling the subscription should've unblocked the publisher")
+	}
+	if _, open := <-events; open {
+		t.Error("expected the channel to be closed")
+	}
+	// Cancelling more than once should not panic
+	cancel()
+	cache.Set("2", "v2")
+}
@@ -0,0 +1,101 @@
+package gocache
+
+import (
+	"slices"
+	"time"
+)
+
+// SetWithTags creates or updates a key with a given value and expiration time, and associates the entry with the
+// tags passed as parameter, which allows every entry associated with a given tag to be deleted using InvalidateTag.
+//
+// If the entry already exists, its tags are replaced by the tags passed as parameter. Note that updating an entry
+// through any other function (e.g. Set) leaves its tags untouched.
+//
+// If the write is rejected (see TrySetWithTTL), the key is deleted, like with SetWithTTL.
+func (cache *Cache) SetWithTags(key string, value any, ttl time.Duration, tags ...string) {
+	value = cache.normalizeValue(value)
+	cache.mutex.Lock()
+	defer cache.unlock()
+	if entry := cache.set(key, value, ttl); entry != nil {
+		cache.untag(entry)
+		cache.tag(entry, tags)
+	}
+	cache.evictIfNecessary()
+}
+
+// TrySetWithTags creates or updates a key with a given value and expiration time, and associates the entry with the
+// tags passed as parameter (see SetWithTags), but unlike SetWithTags, it returns an error if the write was rejected,
+// in which case the cache is left unchanged (see TrySetWithTTL).
+func (cache *Cache) TrySetWithTags(key string, value any, ttl time.Duration, tags ...string) error {
+	if ttl != useDefaultTTL && ttl != NoExpiration && ttl < 1 {
+		return ErrInvalidTTL
+	}
+	value = cache.normalizeValue(value)
+	cache.mutex.Lock()
+	defer cache.unlock()
+	entry, err := cache.trySet(key, value, ttl, useCostFunc)
+	if err == nil {
+		if entry != nil {
+			cache.untag(entry)
+			cache.tag(entry, tags)
+		}
+		cache.evictIfNecessary()
+	}
+	return err
+}
+
+// InvalidateTag deletes every entry associated with the tag passed as parameter, and returns the number of entries
+// deleted.
+//
+// This runs in time proportional to the number of entries associated with the tag, rather than to the number of
+// entries in the cache.
+func (cache *Cache) InvalidateTag(tag string) int {
+	cache.mutex.Lock()
+	keys := cache.tags[tag]
+	numberOfKeysDeleted := 0
+	for key := range keys {
+		if cache.delete(key) {
+			numberOfKeysDeleted++
+		}
+	}
+	cache.unlock()
+	return numberOfKeysDeleted
+}
+
+// tag associates an entry with the tags passed as parameter
+//
+// Must be called while the cache's lock is held.
+func (cache *Cache) tag(entry *Entry, tags []string) {
+	if len(tags) == 0 {
+		return
+	}
+	if cache.tags == nil {
+		cache.tags = make(map[string]map[string]struct{})
+	}
+	entry.meta().tags = slices.Compact(slices.Sorted(slices.Values(tags)))
+	for _, tag := range entry.metadata.tags {
+		keys, ok := cache.tags[tag]
+		if !ok {
+			keys = make(map[string]struct{})
+			cache.tags[tag] = keys
+		}
+		keys[entry.Key] = struct{}{}
+	}
+}
+
+// untag removes every association between an entry and its tags
+//
+// Must be called while the cache's lock is held.
+func (cache *Cache) untag(entry *Entry) {
+	if entry.metadata == nil {
+		return
+	}
+	for _, tag := range entry.metadata.tags {
+		keys := cache.tags[tag]
+		delete(keys, entry.Key)
+		if len(keys) == 0 {
+			delete(cache.tags, tag)
+		}
+	}
+	entry.metadata.tags = nil
+}
@@ -0,0 +1,105 @@
+package gocache
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCache_InvalidateTag(t *testing.T) {
+	cache := NewCache()
+	cache.SetWithTags("user:1", "john", NoExpiration, "users", "admins")
+	cache.SetWithTags("user:2", "jane", NoExpiration, "users")
+	cache.SetWithTags("product:1", "apple", NoExpiration, "products")
+	cache.Set("untagged", "value")
+	if numberOfKeysDeleted := cache.InvalidateTag("users"); numberOfKeysDeleted != 2 {
+		t.Errorf("expected 2 keys to have been deleted, got %d", numberOfKeysDeleted)
+	}
+	if cache.Count() != 2 {
+		t.Errorf("expected 2 keys to be left, got %d", cache.Count())
+	}
+	if _, exists := cache.Get("product:1"); !exists {
+		t.Error("expected product:1 to still exist")
+	}
+	if numberOfKeysDeleted := cache.InvalidateTag("users"); numberOfKeysDeleted != 0 {
+		t.Errorf("expected no keys to have been deleted, got %d", numberOfKeysDeleted)
+	}
+	if numberOfKeysDeleted := cache.InvalidateTag("admins"); numberOfKeysDeleted != 0 {
+		t.Errorf("expected no keys to have been deleted, because user:1 was already deleted, got %d", numberOfKeysDeleted)
+	}
+	if len(cache.tags) != 1 {
+		t.Errorf("expected only the products tag to be left in the index, got %v", cache.tags)
+	}
+}
+
+func TestCache_SetWithTagsReplacesTags(t *testing.T) {
+	cache := NewCache()
+	cache.SetWithTags("key", "v1", NoExpiration, "a", "b")
+	cache.SetWithTags("key", "v2", NoExpiration, "c")
+	if numberOfKeysDeleted := cache.InvalidateTag("a"); numberOfKeysDeleted != 0 {
+		t.Errorf("expected no keys to be deleted, because the tags were replaced, got %d", numberOfKeysDeleted)
+	}
+	// Updating the entry without tags keeps the existing tags
+	cache.Set("key", "v3")
+	if numberOfKeysDeleted := cache.InvalidateTag("c"); numberOfKeysDeleted != 1 {
+		t.Errorf("expected 1 key to be deleted, got %d", numberOfKeysDeleted)
+	}
+}
+
+func TestCache_TrySetWithTags(t *testing.T) {
+	cache := NewCache().WithMaxEntrySize(100)
+	if err := cache.TrySetWithTags("user:1", "john", NoExpiration, "users"); err != nil {
+		t.Fatal(err)
+	}
+	if err := cache.TrySetWithTags("user:1", strings.Repeat("a", 200), NoExpiration, "admins"); err != ErrValueTooLarge {
+		t.Errorf("expected %v, got %v", ErrValueTooLarge, err)
+	}
+	if err := cache.TrySetWithTags("user:1", "jane", 0, "admins"); err != ErrInvalidTTL {
+		t.Errorf("expected %v, got %v", ErrInvalidTTL, err)
+	}
+	if value := cache.GetValue("user:1"); value != "john" {
+		t.Errorf("expected the value to have been left unchanged, got %v", value)
+	}
+	if cache.InvalidateTag("admins") != 0 || cache.InvalidateTag("users") != 1 {
+		t.Error("expected the tags to have been left unchanged")
+	}
+	// SetWithTags deletes the key instead
+	cache.SetWithTags("user:2", "john", NoExpiration, "users")
+	cache.SetWithTags("user:2", strings.Repeat("a", 200), NoExpiration, "users")
+	if _, exists := cache.Get("user:2"); exists {
+		t.Error("expected the existing entry to have been deleted, because its new value was rejected")
+	}
+}
+
+func TestCache_TagIndexIsMaintainedOnRemoval(t *testing.T) {
+	cache := NewCache().WithMaxSize(2)
+	cache.SetWithTags("evicted", "value", NoExpiration, "tag")
+	cache.SetWithTags("deleted", "value", NoExpiration, "tag")
+	cache.SetWithTags("expired", "value", time.Nanosecond, "tag")
+	cache.Delete("deleted")
+	time.Sleep(time.Millisecond)
+	cache.Get("expired")
+	if len(cache.tags) != 0 {
+		t.Errorf("expected tag index to be empty, got %v", cache.tags)
+	}
+	cache.SetWithTags("cleared", "value", NoExpiration, "tag")
+	cache.Clear()
+	if len(cache.tags) != 0 {
+		t.Errorf("expected tag index to be empty, got %v", cache.tags)
+	}
+	// A key that was removed and created again without tags must not be deleted by InvalidateTag
+	cache.SetWithTags("key", "value", NoExpiration, "tag")
+	cache.Delete("key")
+	cache.Set("key", "value")
+	if numberOfKeysDeleted := cache.InvalidateTag("tag"); numberOfKeysDeleted != 0 {
+		t.Errorf("expected no keys to be deleted, got %d", numberOfKeysDeleted)
+	}
+}
+
+func TestCache_InvalidateTagWithOnEvicted(t *testing.T) {
+	recorder := &removalRecorder{}
+	cache := NewCache().WithOnEvicted(recorder.record)
+	cache.SetWithTags("key", "value", NoExpiration, "tag")
+	cache.InvalidateTag("tag")
+	recorder.expect(t, removal{key: "key", value: "value", reason: Deleted})
+}
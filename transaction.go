@@ -0,0 +1,144 @@
+package gocache
+
+import "time"
+
+// Tx is a transaction created by Cache.Transaction
+//
+// Writes made through a Tx are staged and only applied to the cache once the function passed to Cache.Transaction
+// returns nil. A Tx must not be used after that function has returned.
+type Tx struct {
+	cache *Cache
+
+	// prefix is the prefix added to every key passed to the Tx, which is used to scope a Tx to a Namespace
+	prefix string
+
+	// defaultTTL is the TTL used by Set
+	defaultTTL time.Duration
+
+	// writes are the staged writes, indexed by key
+	writes map[string]*stagedWrite
+
+	// keys are the keys of the staged writes, in the order in which they were first written
+	keys []string
+}
+
+// stagedWrite is a write that has yet to be committed to the cache
+type stagedWrite struct {
+	value   any
+	ttl     time.Duration
+	deleted bool
+
+	// size and cost are the size and the cost of the entry, which are computed when the transaction is committed
+	size int
+	cost int
+}
+
+// Transaction executes the function passed as parameter with a Tx that can be used to read and write multiple keys,
+// and then, if the function returns nil, commits every write made through the Tx atomically. If the function
+// returns an error, every write made through the Tx is discarded and the error is returned.
+//
+// The cache's lock is held for the entire duration of the transaction, which means that readers never observe a
+// partial update, and that no other operation can happen on the cache until the transaction is over. This also
+// means that the function must NOT call any of the cache's functions, otherwise it will cause a deadlock; use the
+// Tx instead.
+//
+// If any of the staged writes would be rejected (see TrySetWithTTL), none of them are committed, and the error
+// explaining why the write would be rejected is returned. Evictions, if necessary, only take place once every write
+// has been committed.
+//
+//	err := cache.Transaction(func(tx *gocache.Tx) error {
+//		user, exists := tx.Get("user:1")
+//		if !exists {
+//			return errors.New("user not found")
+//		}
+//		tx.Delete("user-by-email:" + user.(User).Email)
+//		tx.Set("user-by-email:"+newEmail, "user:1")
+//		tx.Set("user:1", User{Email: newEmail})
+//		return nil
+//	})
+func (cache *Cache) Transaction(fn func(tx *Tx) error) error {
+	return cache.transaction("", useDefaultTTL, fn)
+}
+
+func (cache *Cache) transaction(prefix string, defaultTTL time.Duration, fn func(tx *Tx) error) error {
+	tx := &Tx{cache: cache, prefix: prefix, defaultTTL: defaultTTL, writes: make(map[string]*stagedWrite)}
+	cache.mutex.Lock()
+	// The lock must be released even if fn panics
+	defer cache.unlock()
+	if err := fn(tx); err != nil {
+		return err
+	}
+	// Every write must be admitted before any of them is applied, otherwise the transaction would be partially applied
+	for _, key := range tx.keys {
+		write := tx.writes[key]
+		if write.deleted {
+			continue
+		}
+		write.size, write.cost = cache.measure(key, write.value, useCostFunc)
+		if err := cache.checkAdmission(key, write.value, write.size, write.cost); err != nil {
+			return err
+		}
+	}
+	for _, key := range tx.keys {
+		write := tx.writes[key]
+		if write.deleted {
+			cache.delete(key)
+		} else {
+			cache.setWithTTL(key, write.value, write.ttl, write.size, write.cost)
+		}
+	}
+	cache.evictIfNecessary()
+	return nil
+}
+
+// Get retrieves the value of a key, taking into consideration the writes staged in the transaction
+//
+// Unlike Cache.Get, this does not count as accessing the entry, nor does it affect the statistics.
+func (tx *Tx) Get(key string) (any, bool) {
+	key = tx.prefix + key
+	if write, ok := tx.writes[key]; ok {
+		if write.deleted {
+			return nil, false
+		}
+		return write.value, true
+	}
+	entry, ok := tx.cache.get(key)
+	if !ok || entry.Expired() {
+		return nil, false
+	}
+	return entry.Value, true
+}
+
+// Set stages the creation or update of a key with a given value using the default TTL of the namespace if the Tx was
+// created by Namespace.Transaction, or the same default TTL as Cache.Set otherwise
+func (tx *Tx) Set(key string, value any) {
+	tx.SetWithTTL(key, value, tx.defaultTTL)
+}
+
+// SetWithTTL stages the creation or update of a key with a given value and expiration time
+//
+// As with Cache.SetWithTTL, a TTL of 0 or a negative TTL other than NoExpiration deletes the key.
+func (tx *Tx) SetWithTTL(key string, value any, ttl time.Duration) {
+	if ttl != NoExpiration && ttl != useDefaultTTL && ttl < 1 {
+		tx.Delete(key)
+		return
+	}
+	tx.stage(key, &stagedWrite{value: tx.cache.normalizeValue(value), ttl: ttl})
+}
+
+// Delete stages the deletion of a key
+//
+// Returns false if the key did not exist, taking into consideration the writes staged in the transaction.
+func (tx *Tx) Delete(key string) bool {
+	_, exists := tx.Get(key)
+	tx.stage(key, &stagedWrite{deleted: true})
+	return exists
+}
+
+func (tx *Tx) stage(key string, write *stagedWrite) {
+	key = tx.prefix + key
+	if _, ok := tx.writes[key]; !ok {
+		tx.keys = append(tx.keys, key)
+	}
+	tx.writes[key] = write
+}
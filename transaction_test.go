@@ -0,0 +1,167 @@
+package gocache
+
+import (
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestCache_Transaction(t *testing.T) {
+	cache := NewCache()
+	cache.Set("user:1", "john@example.com")
+	cache.Set("user-by-email:john@example.com", "user:1")
+	err := cache.Transaction(func(tx *Tx) error {
+		oldEmail, exists := tx.Get("user:1")
+		if !exists {
+			return errors.New("user not found")
+		}
+		if !tx.Delete("user-by-email:" + oldEmail.(string)) {
+			t.Error("expected Delete to return true, because the key exists")
+		}
+		if _, exists := tx.Get("user-by-email:" + oldEmail.(string)); exists {
+			t.Error("expected staged deletion to be visible within the transaction")
+		}
+		tx.SetWithTTL("user-by-email:jane@example.com", "user:1", time.Hour)
+		tx.Set("user:1", "jane@example.com")
+		if value, _ := tx.Get("user:1"); value != "jane@example.com" {
+			t.Errorf("expected staged write to be visible within the transaction, got %v", value)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if value := cache.GetValue("user:1"); value != "jane@example.com" {
+		t.Errorf("expected %s, got %v", "jane@example.com", value)
+	}
+	if _, exists := cache.Get("user-by-email:john@example.com"); exists {
+		t.Error("expected old index entry to have been deleted")
+	}
+	if ttl, err := cache.TTL("user-by-email:jane@example.com"); err != nil || ttl < 59*time.Minute {
+		t.Errorf("expected new index entry to have a TTL of almost an hour, got %s (err=%v)", ttl, err)
+	}
+}
+
+func TestCache_TransactionWhenFunctionReturnsError(t *testing.T) {
+	cache := NewCache()
+	cache.Set("1", "v1")
+	expectedErr := errors.New("oops")
+	err := cache.Transaction(func(tx *Tx) error {
+		tx.Set("1", "v2")
+		tx.Set("2", "v2")
+		tx.Delete("1")
+		return expectedErr
+	})
+	if err != expectedErr {
+		t.Errorf("expected %v, got %v", expectedErr, err)
+	}
+	if value := cache.GetValue("1"); value != "v1" {
+		t.Errorf("expected changes to have been discarded, got %v", value)
+	}
+	if _, exists := cache.Get("2"); exists {
+		t.Error("expected changes to have been discarded")
+	}
+}
+
+func TestCache_TransactionWhenWriteIsRejected(t *testing.T) {
+	cache := NewCache().WithMaxMemoryUsage(Kilobyte)
+	cache.Set("1", "v1")
+	err := cache.Transaction(func(tx *Tx) error {
+		tx.Set("1", "v2")
+		tx.Set("2", strings.Repeat("a", 2*Kilobyte))
+		return nil
+	})
+	if !errors.Is(err, ErrValueTooLarge) {
+		t.Errorf("expected %v, got %v", ErrValueTooLarge, err)
+	}
+	if value := cache.GetValue("1"); value != "v1" {
+		t.Errorf("expected no write to have been committed, got %v", value)
+	}
+	if _, exists := cache.Get("2"); exists {
+		t.Error("expected the rejected write not to have been committed")
+	}
+}
+
+func TestCache_TransactionWhenFunctionPanics(t *testing.T) {
+	cache := NewCache()
+	cache.Set("1", "v1")
+	func() {
+		defer func() {
+			if r := recover(); r == nil {
+				t.Error("expected the panic to be propagated to the caller")
+			}
+		}()
+		_ = cache.Transaction(func(tx *Tx) error {
+			tx.Set("1", "v2")
+			panic("oops")
+		})
+	}()
+	// If the lock was not released, this would deadlock
+	if value := cache.GetValue("1"); value != "v1" {
+		t.Errorf("expected changes to have been discarded, got %v", value)
+	}
+}
+
+func TestCache_TransactionEvictsAfterCommit(t *testing.T) {
+	cache := NewCache().WithMaxSize(2)
+	cache.Set("old", "value")
+	err := cache.Transaction(func(tx *Tx) error {
+		tx.Set("1", "v1")
+		tx.Set("2", "v2")
+		tx.Set("3", "v3")
+		tx.SetWithTTL("3", "v3", 0)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cache.Count() != 2 {
+		t.Errorf("expected cache to contain 2 entries, got %d", cache.Count())
+	}
+	if _, exists := cache.Get("old"); exists {
+		t.Error("expected old to have been evicted")
+	}
+	if cache.GetValue("1") != "v1" || cache.GetValue("2") != "v2" {
+		t.Error("expected 1 and 2 to exist")
+	}
+	if cache.Stats().EvictedKeys != 1 {
+		t.Errorf("expected 1 eviction, got %d", cache.Stats().EvictedKeys)
+	}
+}
+
+func TestCache_TransactionIsAtomic(t *testing.T) {
+	cache := NewCache()
+	cache.SetAll(map[string]any{"a": 50, "b": 50})
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			_ = cache.Transaction(func(tx *Tx) error {
+				a, _ := tx.Get("a")
+				b, _ := tx.Get("b")
+				tx.Set("a", a.(int)-1)
+				tx.Set("b", b.(int)+1)
+				return nil
+			})
+		}()
+		go func() {
+			defer wg.Done()
+			// Readers must never observe a partial update
+			_ = cache.Transaction(func(tx *Tx) error {
+				a, _ := tx.Get("a")
+				b, _ := tx.Get("b")
+				if a.(int)+b.(int) != 100 {
+					t.Error("expected the sum of a and b to always be 100")
+				}
+				return nil
+			})
+		}()
+	}
+	wg.Wait()
+	if cache.GetValue("a") != 0 || cache.GetValue("b") != 100 {
+		t.Errorf("expected a=0 and b=100, got a=%v and b=%v", cache.GetValue("a"), cache.GetValue("b"))
+	}
+}
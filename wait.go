@@ -0,0 +1,77 @@
+package gocache
+
+import "context"
+
+// waiter is shared by every goroutine waiting for the same key through Cache.WaitFor
+type waiter struct {
+	// channel is closed once the key has been set
+	channel chan struct{}
+
+	// value is the value the key was set to. It must only be read after channel has been closed.
+	value any
+
+	// count is the number of goroutines currently waiting on the waiter
+	count int
+}
+
+// WaitFor retrieves the value of an entry using the key passed as parameter, and if there is no such entry, blocks
+// until the entry is created by Set, SetWithTTL, SetAll, SetAllWithTTL or any other function that sets an entry,
+// or until the context passed as parameter is done.
+//
+// If the context is done before the key is set, the error returned is the context's error.
+//
+// Note that WaitFor does not count as a hit or a miss, nor does it count as accessing the entry (if LRU).
+//
+//	// goroutine 1
+//	value, err := cache.WaitFor(ctx, "result")
+//	// goroutine 2
+//	cache.Set("result", compute())
+func (cache *Cache) WaitFor(ctx context.Context, key string) (any, error) {
+	cache.mutex.Lock()
+	if entry, ok := cache.get(key); ok && !entry.Expired() {
+		value := entry.Value
+		cache.mutex.Unlock()
+		return value, nil
+	}
+	w, ok := cache.waiters[key]
+	if !ok {
+		w = &waiter{channel: make(chan struct{})}
+		if cache.waiters == nil {
+			cache.waiters = make(map[string]*waiter)
+		}
+		cache.waiters[key] = w
+	}
+	w.count++
+	cache.mutex.Unlock()
+	select {
+	case <-w.channel:
+		return w.value, nil
+	case <-ctx.Done():
+		cache.mutex.Lock()
+		w.count--
+		// If the waiter is still registered and nobody else is waiting on it, we need to get rid of it,
+		// otherwise every key that was waited for but never set would leak a waiter
+		if w.count == 0 && cache.waiters[key] == w {
+			delete(cache.waiters, key)
+		}
+		cache.mutex.Unlock()
+		// The key may have been set right before we acquired the lock
+		select {
+		case <-w.channel:
+			return w.value, nil
+		default:
+			return nil, ctx.Err()
+		}
+	}
+}
+
+// wakeWaiters unblocks every goroutine waiting for the key passed as parameter through Cache.WaitFor
+//
+// Must be called while the cache's lock is held.
+func (cache *Cache) wakeWaiters(key string, value any) {
+	if w, ok := cache.waiters[key]; ok {
+		w.value = value
+		close(w.channel)
+		delete(cache.waiters, key)
+	}
+}
@@ -0,0 +1,87 @@
+package gocache
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestCache_WaitFor(t *testing.T) {
+	cache := NewCache()
+	var wg sync.WaitGroup
+	values := make([]any, 3)
+	for i := range values {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			value, err := cache.WaitFor(context.Background(), "key")
+			if err != nil {
+				t.Error(err)
+			}
+			values[i] = value
+		}(i)
+	}
+	time.Sleep(10 * time.Millisecond)
+	cache.Set("key", "value")
+	wg.Wait()
+	for i, value := range values {
+		if value != "value" {
+			t.Errorf("expected waiter #%d to receive %s, got %v", i, "value", value)
+		}
+	}
+	if len(cache.waiters) != 0 {
+		t.Errorf("expected no waiters to be left, got %d", len(cache.waiters))
+	}
+}
+
+func TestCache_WaitForWhenKeyAlreadyExists(t *testing.T) {
+	cache := NewCache()
+	cache.Set("key", "value")
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	value, err := cache.WaitFor(ctx, "key")
+	if err != nil {
+		t.Fatal("expected no error, because the key already exists, got", err)
+	}
+	if value != "value" {
+		t.Errorf("expected %s, got %v", "value", value)
+	}
+}
+
+func TestCache_WaitForWhenKeyIsExpired(t *testing.T) {
+	cache := NewCache()
+	cache.SetWithTTL("key", "old-value", time.Nanosecond)
+	time.Sleep(time.Millisecond)
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		cache.SetAll(map[string]any{"key": "new-value"})
+	}()
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	value, err := cache.WaitFor(ctx, "key")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if value != "new-value" {
+		t.Errorf("expected %s, got %v", "new-value", value)
+	}
+}
+
+func TestCache_WaitForWhenContextIsDone(t *testing.T) {
+	cache := NewCache()
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	value, err := cache.WaitFor(ctx, "key")
+	if err != context.DeadlineExceeded {
+		t.Errorf("expected %v, got %v", context.DeadlineExceeded, err)
+	}
+	if value != nil {
+		t.Errorf("expected nil, got %v", value)
+	}
+	if len(cache.waiters) != 0 {
+		t.Errorf("expected waiter to have been cleaned up, but %d waiters are left", len(cache.waiters))
+	}
+	// Setting the key afterward must not panic
+	cache.Set("key", "value")
+}